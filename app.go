@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"transcube-webapp/internal/platform"
 	"transcube-webapp/internal/services"
 	"transcube-webapp/internal/types"
 	"transcube-webapp/internal/utils"
@@ -14,19 +20,6 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// Progress value constants for task stages
-const (
-	ProgressDownloadStart      = 10
-	ProgressMetadataFetched    = 30
-	ProgressVideoDownloaded    = 45
-	ProgressAudioExtracted     = 60
-	ProgressTranscribeStart    = 60
-	ProgressTranscribeComplete = 80
-	ProgressSummarizeStart     = 85
-	ProgressSummarizeComplete  = 95
-	ProgressTaskComplete       = 100
-)
-
 // App struct
 type App struct {
 	ctx           context.Context
@@ -40,6 +33,7 @@ type App struct {
 	summarizer    *services.OpenRouterClient
 	settings      types.Settings
 	settingsStore *services.SettingsStore
+	subscriptions *services.Subscriptions
 }
 
 // NewApp creates a new App application struct
@@ -52,11 +46,12 @@ func NewApp() *App {
 
 	storage := services.NewStorage("")
 	ss, _ := services.NewSettingsStore()
-	return &App{
+	downloader := services.NewDownloader(storage)
+	app := &App{
 		depChecker:  services.NewDependencyChecker(),
 		storage:     storage,
 		taskManager: services.NewTaskManager(storage),
-		downloader:  services.NewDownloader(storage),
+		downloader:  downloader,
 		yapRunner:   services.NewYapRunner(storage),
 		mediaServer: services.NewMediaServer(storage),
 		logger:      logger,
@@ -71,9 +66,18 @@ func NewApp() *App {
 			Temperature:          0.3,
 			MaxTokens:            4096,
 			ChannelLanguagePrefs: make(map[string]string),
+
+			MaxConcurrentDownloads:      services.DefaultDownloadWorkers,
+			MaxConcurrentTranscriptions: services.DefaultTranscriptionWorkers,
+			MaxConcurrentSummaries:      services.DefaultSummaryWorkers,
+			DownloadMode:                types.DownloadModeVideo,
+			FormatPresets:               services.DefaultFormatPresets(),
+			DefaultFormatPreset:         services.DefaultFormatPresetName,
 		},
 		settingsStore: ss,
 	}
+	app.subscriptions = services.NewSubscriptions(ss, downloader, storage, app.enqueueUpload, app.GetChannelLanguagePreference)
+	return app
 }
 
 // startup is called when the app starts. The context is saved
@@ -108,6 +112,11 @@ func (a *App) startup(ctx context.Context) {
 			if a.settings.ChannelLanguagePrefs == nil {
 				a.settings.ChannelLanguagePrefs = make(map[string]string)
 			}
+			a.normalizeFormatPresets(&a.settings)
+			if err := services.ValidateNetworkSettings(a.settings.Network); err != nil {
+				a.logger.Warn("Ignoring invalid network settings", "error", err)
+				a.settings.Network = types.NetworkSettings{}
+			}
 			// keep storage workspace in sync
 			if a.settings.Workspace != "" {
 				a.storage.SetWorkspace(a.settings.Workspace)
@@ -118,12 +127,48 @@ func (a *App) startup(ctx context.Context) {
 		}
 	}
 
+	a.applyQueueSettings()
+	a.applyNetworkSettings()
+	a.downloader.SetAuth(a.settings.Auth)
+	a.taskManager.SetEventEmitter(func(event string, data any) {
+		runtime.EventsEmit(a.ctx, event, data)
+	})
+
 	// Log dependency status
 	deps := a.depChecker.Check()
 	a.logger.Info("Dependency check",
 		"yt-dlp", deps.YtDlp,
 		"ffmpeg", deps.FFmpeg,
+		"ffprobe", deps.FFprobe,
 		"yap", deps.Yap)
+
+	if migrated, err := a.taskManager.MigrateMetadata(); err != nil {
+		a.logger.Warn("Failed to migrate task metadata", "error", err)
+	} else if migrated > 0 {
+		a.logger.Info("Migrated task metadata", "tasks", migrated)
+	}
+	a.recoverTasks()
+
+	if err := a.subscriptions.Load(); err != nil {
+		a.logger.Warn("Failed to load subscriptions", "error", err)
+	}
+	go a.subscriptions.Run(ctx, a.subscriptionInterval)
+}
+
+// applyQueueSettings pushes the configured per-stage concurrency to the queue
+func (a *App) applyQueueSettings() {
+	a.taskManager.SetStageConcurrency(types.TaskStatusDownloading, a.settings.MaxConcurrentDownloads)
+	a.taskManager.SetStageConcurrency(types.TaskStatusTranscribing, a.settings.MaxConcurrentTranscriptions)
+	a.taskManager.SetStageConcurrency(types.TaskStatusSummarizing, a.settings.MaxConcurrentSummaries)
+}
+
+// applyNetworkSettings hands the proxy, rate limit and retry settings to
+// yt-dlp and the proxy to the summarization client
+func (a *App) applyNetworkSettings() {
+	a.downloader.SetNetwork(a.settings.Network)
+	if err := a.summarizer.SetProxy(a.settings.Network.Proxy); err != nil {
+		a.logger.Warn("Failed to apply proxy to summarization client", "error", err)
+	}
 }
 
 // CheckDependencies checks if required tools are installed
@@ -131,6 +176,76 @@ func (a *App) CheckDependencies() types.DependencyStatus {
 	return a.depChecker.Check()
 }
 
+// SetPlatformAuth sets the cookies yt-dlp signs in to a platform with. A
+// cookies file is copied into the app's config directory and the copy is
+// used; passing an empty PlatformAuth signs out of the platform.
+func (a *App) SetPlatformAuth(platformName string, auth types.PlatformAuth) (types.Settings, error) {
+	switch platform.PlatformName(platformName) {
+	case "", platform.Local, platform.Unknown:
+		return a.settings, fmt.Errorf("cannot sign in to platform %q", platformName)
+	}
+	if err := services.ValidatePlatformAuth(auth); err != nil {
+		return a.settings, err
+	}
+	if a.settingsStore == nil {
+		return a.settings, fmt.Errorf("settings cannot be saved, so cookies cannot be stored")
+	}
+
+	if auth.CookiesFile != "" && !a.settingsStore.IsImportedCookies(auth.CookiesFile) {
+		path, err := a.settingsStore.ImportCookies(platformName, auth.CookiesFile)
+		if err != nil {
+			return a.settings, err
+		}
+		auth.CookiesFile = path
+	} else if auth.CookiesFile == "" {
+		if err := a.settingsStore.RemoveCookies(platformName); err != nil {
+			a.logger.Warn("Failed to remove cookies file", "platform", platformName, "error", err)
+		}
+	}
+
+	if a.settings.Auth == nil {
+		a.settings.Auth = make(map[string]types.PlatformAuth)
+	}
+	if auth == (types.PlatformAuth{}) {
+		delete(a.settings.Auth, platformName)
+	} else {
+		a.settings.Auth[platformName] = auth
+	}
+	a.downloader.SetAuth(a.settings.Auth)
+
+	if err := a.settingsStore.Save(a.settings); err != nil {
+		return a.settings, fmt.Errorf("failed to save settings: %w", err)
+	}
+	a.logger.Info("Platform authentication updated", "platform", platformName,
+		"cookiesFile", auth.CookiesFile != "", "browser", auth.CookiesFromBrowser)
+	return a.settings, nil
+}
+
+// normalizeFormatPresets drops format presets yt-dlp could not use and falls
+// back to the built-in presets when none are left
+func (a *App) normalizeFormatPresets(settings *types.Settings) {
+	presets := make([]types.FormatPreset, 0, len(settings.FormatPresets))
+	for _, preset := range settings.FormatPresets {
+		if err := services.ValidateFormatPreset(preset); err != nil {
+			a.logger.Warn("Ignoring invalid format preset", "error", err)
+			continue
+		}
+		if slices.ContainsFunc(presets, func(p types.FormatPreset) bool { return p.Name == preset.Name }) {
+			a.logger.Warn("Ignoring duplicate format preset", "name", preset.Name)
+			continue
+		}
+		presets = append(presets, preset)
+	}
+	if len(presets) == 0 {
+		presets = services.DefaultFormatPresets()
+	}
+	settings.FormatPresets = presets
+
+	if _, ok := services.FindFormatPreset(presets, settings.DefaultFormatPreset); !ok {
+		settings.DefaultFormatPreset = services.DefaultFormatPresetName
+	}
+}
+
 // GetSettings returns current application settings
 func (a *App) GetSettings() types.Settings {
 	// keep workspace in sync
@@ -138,25 +253,34 @@ func (a *App) GetSettings() types.Settings {
 	return a.settings
 }
 
-// UpdateSettings updates application settings
-func (a *App) UpdateSettings(settings types.Settings) types.Settings {
+// UpdateSettings updates application settings. Authentication is kept as is;
+// it is changed with SetPlatformAuth. Invalid network settings are rejected
+// and nothing is changed.
+func (a *App) UpdateSettings(settings types.Settings) (types.Settings, error) {
+	if err := services.ValidateNetworkSettings(settings.Network); err != nil {
+		return a.settings, err
+	}
 	if settings.Workspace != "" {
 		a.storage.SetWorkspace(settings.Workspace)
 		if err := a.storage.EnsureWorkspace(); err != nil {
 			slog.Error("ensure workspace", "error", err)
 		}
 	}
+	a.normalizeFormatPresets(&settings)
+	settings.Auth = a.settings.Auth
 	// store in memory (could be persisted later)
 	a.settings = settings
 	// ensure workspace reflects current storage
 	a.settings.Workspace = a.storage.GetWorkspace()
+	a.applyQueueSettings()
+	a.applyNetworkSettings()
 	// persist to disk
 	if a.settingsStore != nil {
 		if err := a.settingsStore.Save(a.settings); err != nil {
 			a.logger.Warn("Failed to persist settings", "error", err)
 		}
 	}
-	return a.settings
+	return a.settings, nil
 }
 
 // buildChannelKey creates a unique key for channel language preferences
@@ -206,12 +330,26 @@ func (a *App) DetectPlatform(url string) string {
 func (a *App) ParseVideoUrl(url string) (*types.VideoMetadata, error) {
 	a.logger.Debug("Parsing video URL", "url", url)
 
-	info, err := a.downloader.GetVideoInfo(url)
+	info, err := a.downloader.GetVideoInfo(a.ctx, url)
 	if err != nil {
 		a.logger.Error("Failed to get video info", "url", url, "error", err)
 		return nil, err
 	}
 
+	meta := a.videoMetadata(url, info)
+	a.logger.Info("Video metadata retrieved",
+		"id", meta.ID,
+		"title", meta.Title,
+		"channel", meta.Channel,
+		"duration", meta.Duration,
+		"views", meta.ViewCount,
+		"likes", meta.LikeCount,
+		"publishedAt", meta.PublishedAt)
+	return &meta, nil
+}
+
+// videoMetadata converts what yt-dlp reported about the video at url
+func (a *App) videoMetadata(url string, info *services.VideoInfo) types.VideoMetadata {
 	publishedAt := ""
 	if info.UploadDate != "" {
 		if parsed, err := time.Parse("20060102", info.UploadDate); err == nil {
@@ -227,20 +365,9 @@ func (a *App) ParseVideoUrl(url string) (*types.VideoMetadata, error) {
 		publishedAt = time.Unix(info.ReleaseTS, 0).UTC().Format(time.RFC3339)
 	}
 
-	a.logger.Info("Video metadata retrieved",
-		"id", info.ID,
-		"title", info.Title,
-		"channel", info.Channel,
-		"duration", info.Duration,
-		"views", info.ViewCount,
-		"likes", info.LikeCount,
-		"publishedAt", publishedAt)
-
-	platform := a.downloader.DetectPlatform(url)
-
-	return &types.VideoMetadata{
+	return types.VideoMetadata{
 		ID:          info.ID,
-		Platform:    platform,
+		Platform:    a.downloader.DetectPlatform(url),
 		Title:       info.Title,
 		Channel:     info.Channel,
 		ChannelID:   info.ChannelID,
@@ -250,14 +377,31 @@ func (a *App) ParseVideoUrl(url string) (*types.VideoMetadata, error) {
 		ViewCount:   info.ViewCount,
 		LikeCount:   info.LikeCount,
 		Description: info.Description,
-	}, nil
+		Tags:        info.Tags,
+	}
 }
 
-// StartTranscription starts a new transcription task
-func (a *App) StartTranscription(url string, sourceLang string) (*types.Task, error) {
-	a.logger.Info("Starting new transcription task", "url", url, "sourceLang", sourceLang)
+// StartTranscription starts a new transcription task. formatPreset names the
+// format preset to download in; empty means the default from settings.
+func (a *App) StartTranscription(url string, sourceLang string, formatPreset string) (*types.Task, error) {
+	a.logger.Info("Starting new transcription task", "url", url, "sourceLang", sourceLang, "formatPreset", formatPreset)
+
+	if formatPreset != "" {
+		if _, ok := services.FindFormatPreset(a.settings.FormatPresets, formatPreset); !ok {
+			return nil, fmt.Errorf("unknown format preset: %s", formatPreset)
+		}
+	}
+	canonical, err := a.downloader.ResolveURL(a.ctx, url)
+	if err != nil {
+		a.logger.Error("Failed to resolve video URL", "url", url, "error", err)
+		return nil, err
+	}
+	if canonical != url {
+		a.logger.Debug("Using canonical video URL", "url", url, "canonical", canonical)
+		url = canonical
+	}
 
-	info, err := a.downloader.GetVideoInfo(url)
+	info, err := a.downloader.GetVideoInfo(a.ctx, url)
 	if err != nil {
 		a.logger.Error("Failed to fetch metadata before creating task", "url", url, "error", err)
 		return nil, err
@@ -266,26 +410,14 @@ func (a *App) StartTranscription(url string, sourceLang string) (*types.Task, er
 		return nil, fmt.Errorf("video ID is missing from metadata")
 	}
 
-	duration := time.Duration(info.Duration) * time.Second
-	durationStr := fmt.Sprintf("%02d:%02d", int(duration.Minutes()), int(duration.Seconds())%60)
-
-	platform := a.downloader.DetectPlatform(url)
+	meta := a.videoMetadata(url, info)
 
 	// Save channel language preference
-	if err := a.SetChannelLanguagePreference(platform, info.ChannelID, info.Channel, sourceLang); err != nil {
+	if err := a.SetChannelLanguagePreference(meta.Platform, meta.ChannelID, meta.Channel, sourceLang); err != nil {
 		a.logger.Warn("Failed to save channel language preference", "error", err)
 	}
 
-	task, err := a.taskManager.CreateTask(
-		url,
-		sourceLang,
-		platform,
-		info.ID,
-		info.Title,
-		info.Channel,
-		durationStr,
-		utils.EnsureHTTPS(info.Thumbnail),
-	)
+	task, err := a.taskManager.CreateTask(url, sourceLang, meta)
 	if err != nil {
 		a.logger.Error("Failed to create task", "error", err)
 		return nil, err
@@ -293,10 +425,284 @@ func (a *App) StartTranscription(url string, sourceLang string) (*types.Task, er
 
 	a.logger.Info("Task created", "taskId", task.ID)
 
-	// Start processing in background
+	// The download stage reads the metadata from here instead of fetching it
+	// again
+	if err := a.downloader.SaveVideoInfo(task.WorkDir, info); err != nil {
+		a.logger.Warn("Failed to save video info", "taskId", task.ID, "error", err)
+	}
+
+	if formatPreset != "" {
+		if task, err = a.taskManager.SetTaskFormatPreset(task.ID, formatPreset); err != nil {
+			return nil, err
+		}
+	}
+
+	queued, err := a.taskManager.Enqueue(task.ID)
+	if err != nil {
+		a.logger.Error("Failed to enqueue task", "taskId", task.ID, "error", err)
+		return nil, err
+	}
+
+	// Start processing in background; it waits for a free worker slot
 	go a.processTask(task.ID)
 
-	return task, nil
+	return queued, nil
+}
+
+// ExpandCollection lists the videos of a playlist, channel tab or series so
+// the user can pick which ones to process with StartCollection
+func (a *App) ExpandCollection(url string) (*types.Collection, error) {
+	a.logger.Info("Expanding collection", "url", url)
+
+	info, err := a.downloader.ListPlaylist(a.ctx, url)
+	if err != nil {
+		a.logger.Error("Failed to list collection", "url", url, "error", err)
+		return nil, err
+	}
+
+	platform := a.downloader.DetectPlatform(url)
+	collection := &types.Collection{
+		ID:       fmt.Sprintf("%s:%s", platform, info.ID),
+		Platform: platform,
+		URL:      url,
+		Title:    info.Title,
+		Channel:  info.Channel,
+		Entries:  []types.CollectionEntry{},
+	}
+	for _, entry := range info.Entries {
+		if !entry.IsVideo() {
+			continue
+		}
+		channel := entry.Channel
+		if channel == "" {
+			channel = entry.Uploader
+		}
+		if channel == "" {
+			channel = info.Channel
+		}
+		collection.Entries = append(collection.Entries, types.CollectionEntry{
+			ID:        entry.ID,
+			URL:       entry.URL,
+			Title:     entry.Title,
+			Channel:   channel,
+			Duration:  int(entry.Duration),
+			Thumbnail: utils.EnsureHTTPS(entry.BestThumbnail()),
+		})
+	}
+	if len(collection.Entries) == 0 {
+		return nil, fmt.Errorf("no videos found in %s; for a channel, open one of its tabs such as Videos", url)
+	}
+
+	a.logger.Info("Collection expanded", "collectionId", collection.ID, "entries", len(collection.Entries))
+	return collection, nil
+}
+
+// StartCollection creates and queues one task per entry of the collection.
+// Pass only the entries the user selected. Videos that already have a task
+// are skipped; the tasks that were created are returned.
+func (a *App) StartCollection(collection types.Collection, sourceLang string) ([]*types.Task, error) {
+	if collection.ID == "" {
+		return nil, fmt.Errorf("collection ID is required")
+	}
+	if len(collection.Entries) == 0 {
+		return nil, fmt.Errorf("no videos selected")
+	}
+
+	a.logger.Info("Starting collection", "collectionId", collection.ID, "entries", len(collection.Entries), "sourceLang", sourceLang)
+
+	created := make([]*types.Task, 0, len(collection.Entries))
+	skipped := 0
+	for _, entry := range collection.Entries {
+		task, err := a.taskManager.CreateTask(entry.URL, sourceLang, types.VideoMetadata{
+			ID:        entry.ID,
+			Platform:  a.downloader.DetectPlatform(entry.URL),
+			Title:     entry.Title,
+			Channel:   entry.Channel,
+			Duration:  entry.Duration,
+			Thumbnail: entry.Thumbnail,
+		})
+		if err != nil {
+			a.logger.Warn("Skipping collection entry", "collectionId", collection.ID, "videoId", entry.ID, "error", err)
+			skipped++
+			continue
+		}
+
+		if _, err := a.taskManager.SetTaskCollection(task.ID, collection.ID); err != nil {
+			a.logger.Warn("Failed to record collection of task", "taskId", task.ID, "error", err)
+		}
+
+		queued, err := a.taskManager.Enqueue(task.ID)
+		if err != nil {
+			a.logger.Error("Failed to enqueue task", "taskId", task.ID, "error", err)
+			continue
+		}
+		created = append(created, queued)
+
+		// Start processing in background; it waits for a free worker slot
+		go a.processTask(task.ID)
+	}
+
+	if len(created) == 0 {
+		return nil, fmt.Errorf("no tasks created: all %d selected videos were skipped", skipped)
+	}
+	a.logger.Info("Collection started", "collectionId", collection.ID, "created", len(created), "skipped", skipped)
+	a.emitReloadEvent()
+
+	return created, nil
+}
+
+// ImportLocalFile creates a task for an audio or video file on disk, such as a
+// meeting recording, and runs the pipeline on it. The file is hard-linked or
+// copied into the task directory; its content hash serves as the video ID.
+func (a *App) ImportLocalFile(path string, sourceLang string) (*types.Task, error) {
+	a.logger.Info("Importing local file", "path", path, "sourceLang", sourceLang)
+
+	if path == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+	stat, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file: %w", err)
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", absPath)
+	}
+
+	media, err := a.downloader.ProbeMedia(a.ctx, absPath)
+	if err != nil {
+		a.logger.Error("Failed to probe local file", "path", absPath, "error", err)
+		return nil, err
+	}
+	if !media.HasAudio {
+		return nil, fmt.Errorf("%s has no audio track to transcribe", filepath.Base(absPath))
+	}
+
+	fileID, err := services.LocalFileID(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	fileURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String()
+	title := strings.TrimSuffix(filepath.Base(absPath), filepath.Ext(absPath))
+	task, err := a.taskManager.CreateTask(fileURL, sourceLang, types.VideoMetadata{
+		ID:       fileID,
+		Platform: string(platform.Local),
+		Title:    title,
+		Duration: int(media.Duration),
+	})
+	if err != nil {
+		a.logger.Error("Failed to create task", "error", err)
+		return nil, err
+	}
+
+	sourceFile, err := a.storage.ImportFile(absPath, task.WorkDir)
+	if err == nil {
+		_, err = a.taskManager.SetTaskSourceFile(task.ID, sourceFile)
+	}
+	if err != nil {
+		a.logger.Error("Failed to import file into task", "taskId", task.ID, "error", err)
+		a.taskManager.ClearTask(task.ID)
+		if removeErr := os.RemoveAll(task.WorkDir); removeErr != nil {
+			a.logger.Warn("Failed to remove task directory", "path", task.WorkDir, "error", removeErr)
+		}
+		return nil, err
+	}
+
+	a.logger.Info("Task created", "taskId", task.ID, "sourceFile", sourceFile)
+
+	queued, err := a.taskManager.Enqueue(task.ID)
+	if err != nil {
+		a.logger.Error("Failed to enqueue task", "taskId", task.ID, "error", err)
+		return nil, err
+	}
+
+	// Start processing in background; it waits for a free worker slot
+	go a.processTask(task.ID)
+	a.emitReloadEvent()
+
+	return queued, nil
+}
+
+// ListSubscriptions returns the channels whose new uploads are processed
+// automatically
+func (a *App) ListSubscriptions() []types.Subscription {
+	return a.subscriptions.List()
+}
+
+// AddSubscription subscribes to a channel tab such as a YouTube channel's
+// Videos page. Only uploads published from now on are processed.
+func (a *App) AddSubscription(url string) (*types.Subscription, error) {
+	a.logger.Info("Adding subscription", "url", url)
+
+	sub, err := a.subscriptions.Add(a.ctx, url)
+	if err != nil {
+		a.logger.Error("Failed to add subscription", "url", url, "error", err)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RemoveSubscription stops following a channel. Its existing tasks are kept.
+func (a *App) RemoveSubscription(id string) error {
+	return a.subscriptions.Remove(id)
+}
+
+// SetSubscriptionEnabled turns automatic checks of a subscription on or off
+func (a *App) SetSubscriptionEnabled(id string, enabled bool) (*types.Subscription, error) {
+	return a.subscriptions.SetEnabled(id, enabled)
+}
+
+// CheckSubscriptions checks every enabled subscription for new uploads right
+// away and returns how many tasks were queued
+func (a *App) CheckSubscriptions() (int, error) {
+	queued, err := a.subscriptions.CheckAll(a.ctx)
+	if err != nil {
+		a.logger.Warn("Subscription check finished with errors", "queued", queued, "error", err)
+	}
+	return queued, err
+}
+
+// enqueueUpload creates and queues a task for a new upload of a subscribed
+// channel
+func (a *App) enqueueUpload(sub types.Subscription, entry services.PlaylistEntry, sourceLang string) error {
+	channel := entry.Channel
+	if channel == "" {
+		channel = entry.Uploader
+	}
+
+	task, err := a.taskManager.CreateTask(entry.URL, sourceLang, types.VideoMetadata{
+		ID:        entry.ID,
+		Platform:  sub.Platform,
+		Title:     entry.Title,
+		Channel:   channel,
+		Duration:  int(entry.Duration),
+		Thumbnail: utils.EnsureHTTPS(entry.BestThumbnail()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.taskManager.Enqueue(task.ID); err != nil {
+		return err
+	}
+	a.logger.Info("Queued upload of subscribed channel", "taskId", task.ID, "subscriptionId", sub.ID, "videoId", entry.ID)
+
+	go a.processTask(task.ID)
+	a.emitReloadEvent()
+	return nil
+}
+
+// subscriptionInterval returns the configured time between subscription
+// checks
+func (a *App) subscriptionInterval() time.Duration {
+	if a.settings.SubscriptionIntervalMinutes <= 0 {
+		return services.DefaultSubscriptionInterval
+	}
+	return time.Duration(a.settings.SubscriptionIntervalMinutes) * time.Minute
 }
 
 func (a *App) loadTaskFromDisk(taskID string) (*types.Task, error) {
@@ -314,12 +720,17 @@ func (a *App) loadTaskFromDisk(taskID string) (*types.Task, error) {
 	return nil, fmt.Errorf("task %s not found", taskID)
 }
 
-// GetTask returns the task by ID, loading it from persisted metadata
+// GetTask returns the task by ID. Tasks being processed are served from
+// memory so live progress is visible; others are loaded from persisted
+// metadata.
 func (a *App) GetTask(taskID string) (*types.Task, error) {
 	if taskID == "" {
 		return nil, fmt.Errorf("taskID is required")
 	}
 
+	if task, err := a.taskManager.GetTask(taskID); err == nil {
+		return task, nil
+	}
 	return a.loadTaskFromDisk(taskID)
 }
 
@@ -357,7 +768,11 @@ func (a *App) RetryTask(taskID string) (*types.Task, error) {
 		return nil, err
 	}
 
-	task, err := a.taskManager.RetryTask(taskID)
+	if _, err := a.taskManager.RetryTask(taskID); err != nil {
+		return nil, err
+	}
+
+	task, err := a.taskManager.Enqueue(taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -370,6 +785,139 @@ func (a *App) RetryTask(taskID string) (*types.Task, error) {
 	return task, nil
 }
 
+// GetQueue returns queued and in-flight tasks in the order they will be processed
+func (a *App) GetQueue() ([]*types.Task, error) {
+	ids := a.taskManager.QueuedTaskIDs()
+	queue := make([]*types.Task, 0, len(ids))
+	for _, id := range ids {
+		task, err := a.taskManager.GetTask(id)
+		if err != nil {
+			continue
+		}
+		queue = append(queue, task)
+	}
+	return queue, nil
+}
+
+// MoveQueuedTask moves a task to a new zero-based position in the queue
+func (a *App) MoveQueuedTask(taskID string, position int) ([]*types.Task, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("taskID is required")
+	}
+
+	if err := a.taskManager.MoveQueuedTask(taskID, position); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Queued task moved", "taskId", taskID, "position", position)
+	return a.GetQueue()
+}
+
+// CancelTask stops a pending or running task. Any yt-dlp, ffmpeg or yap
+// process started for it is killed and partially written files are removed
+// by the stage that was interrupted.
+func (a *App) CancelTask(taskID string) (*types.Task, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("taskID is required")
+	}
+
+	if _, err := a.ensureTaskLoaded(taskID); err != nil {
+		return nil, err
+	}
+
+	task, err := a.taskManager.CancelTask(taskID)
+	if err != nil {
+		a.logger.Warn("Cancel rejected", "taskId", taskID, "error", err)
+		return nil, err
+	}
+
+	a.logger.Info("Task cancelled", "taskId", taskID)
+	if task.WorkDir != "" {
+		_ = a.storage.SaveLog(task.WorkDir, "cancel", "Task cancelled by user")
+	}
+	a.emitReloadEvent()
+
+	return task, nil
+}
+
+// PauseTask stops a queued or running task so it can be resumed later. The
+// running tool is killed; partial downloads are kept and picked up again by
+// yt-dlp's --continue when the task resumes.
+func (a *App) PauseTask(taskID string) (*types.Task, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("taskID is required")
+	}
+
+	if _, err := a.ensureTaskLoaded(taskID); err != nil {
+		return nil, err
+	}
+
+	task, err := a.taskManager.PauseTask(taskID)
+	if err != nil {
+		a.logger.Warn("Pause rejected", "taskId", taskID, "error", err)
+		return nil, err
+	}
+	a.taskManager.Dequeue(taskID)
+
+	a.logger.Info("Task paused", "taskId", taskID, "resumeFrom", task.PausedStage)
+	if task.WorkDir != "" {
+		_ = a.storage.SaveLog(task.WorkDir, "pause", fmt.Sprintf("Task paused during %s", task.PausedStage))
+	}
+	a.emitReloadEvent()
+
+	return task, nil
+}
+
+// ResumeTask queues a paused task again, continuing from the stage it was
+// paused in. Paused tasks survive app restarts.
+func (a *App) ResumeTask(taskID string) (*types.Task, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("taskID is required")
+	}
+
+	task, err := a.ensureTaskLoaded(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != types.TaskStatusPaused {
+		return nil, fmt.Errorf("cannot resume task while it is %s", task.Status)
+	}
+
+	from := task.PausedStage
+	if !slices.Contains(pipelineStages, from) {
+		from = types.StageForProgress(task.Progress)
+	}
+
+	// The paused run may still be stopping; resuming next to it would leave
+	// the task queued with nothing to run it
+	ctx, err := a.taskManager.LockTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task is still stopping, try again shortly: %w", err)
+	}
+	started := false
+	defer func() {
+		if !started {
+			a.taskManager.UnlockTask(taskID)
+		}
+	}()
+
+	queued, err := a.taskManager.Enqueue(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Resuming paused task", "taskId", taskID, "from", from)
+	if task.WorkDir != "" {
+		_ = a.storage.SaveLog(task.WorkDir, "pause", fmt.Sprintf("Task resumed from %s", from))
+	}
+
+	started = true
+	go a.runPipeline(ctx, taskID, from, false)
+	a.emitReloadEvent()
+
+	return queued, nil
+}
+
 // UpdateTaskSourceLanguage updates the source language for a task
 func (a *App) UpdateTaskSourceLanguage(taskID string, sourceLang string) (*types.Task, error) {
 	if taskID == "" {
@@ -393,36 +941,36 @@ func (a *App) UpdateTaskSourceLanguage(taskID string, sourceLang string) (*types
 }
 
 // downloadTaskInternal is the internal implementation without lock acquisition
-func (a *App) downloadTaskInternal(taskID string) (*types.Task, error) {
+func (a *App) downloadTaskInternal(ctx context.Context, taskID string, force bool) (*types.Task, error) {
 	task, err := a.ensureTaskLoaded(taskID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := a.taskManager.BeginStage(
-		taskID,
-		types.TaskStatusDownloading,
-		ProgressDownloadStart,
-		types.TaskStatusPending,
-		types.TaskStatusFailed,
-		types.TaskStatusDone,
-	); err != nil {
+	if err := a.taskManager.BeginStage(taskID, types.TaskStatusDownloading, types.ProgressDownloadStart); err != nil {
 		a.logger.Warn("Download stage rejected", "taskId", taskID, "error", err)
 		return nil, err
 	}
 
+	preset := a.downloadPreset(task)
+	mode := preset.Mode()
+	params := a.downloader.DownloadParams(task.URL, preset)
+	if !force && a.stageIsCurrent(task, types.TaskStatusDownloading, params) {
+		return a.skipStage(task, types.TaskStatusDownloading, types.ProgressAudioExtracted, "download")
+	}
+
+	if task.Platform == string(platform.Local) {
+		return a.extractLocalAudio(ctx, task, params)
+	}
+
 	a.logger.Info("Download stage started", "taskId", taskID, "url", task.URL)
 
-	info, err := a.downloader.GetVideoInfo(task.URL)
+	info, err := a.videoInfo(ctx, task, false)
 	if err != nil {
-		a.recordTaskError(taskID, err, "Failed to get video info", "url", task.URL)
-		return nil, err
+		return nil, a.stageError(ctx, taskID, err, "Failed to get video info", "url", task.URL)
 	}
 
-	duration := time.Duration(info.Duration) * time.Second
-	durationStr := fmt.Sprintf("%02d:%02d", int(duration.Minutes()), int(duration.Seconds())%60)
-
-	if err := a.taskManager.UpdateTaskMetadata(taskID, info.ID, info.Title, info.Channel, durationStr, utils.EnsureHTTPS(info.Thumbnail)); err != nil {
+	if err := a.taskManager.UpdateTaskMetadata(taskID, a.videoMetadata(task.URL, info)); err != nil {
 		a.recordTaskError(taskID, err, "Failed to update metadata")
 		return nil, err
 	}
@@ -443,59 +991,252 @@ func (a *App) downloadTaskInternal(taskID string) (*types.Task, error) {
 		return nil, err
 	}
 
-	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDownloading, ProgressMetadataFetched); err != nil {
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDownloading, types.ProgressMetadataFetched); err != nil {
 		return nil, err
 	}
 
-	if err := a.downloader.DownloadVideo(task.URL, workDir); err != nil {
-		a.recordTaskError(taskID, err, "Failed to download video", "url", task.URL)
-		return nil, err
+	download := a.downloader.DownloadVideo
+	if mode == types.DownloadModeAudio {
+		download = a.downloader.DownloadAudio
+	}
+	var result *services.DownloadResult
+	if err := a.withRetry(ctx, workDir, "download", func() error {
+		var downloadErr error
+		result, downloadErr = download(ctx, task.URL, workDir, preset,
+			a.stageProgress(taskID, types.TaskStatusDownloading, types.ProgressMetadataFetched, types.ProgressVideoDownloaded))
+		return downloadErr
+	}); err != nil {
+		return nil, a.stageError(ctx, taskID, err, "Failed to download "+string(mode), "url", task.URL)
 	}
 
-	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDownloading, ProgressVideoDownloaded); err != nil {
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDownloading, types.ProgressVideoDownloaded); err != nil {
 		return nil, err
 	}
 
-	videoPath := fmt.Sprintf("%s/video.mp4", workDir)
-	if _, statErr := os.Stat(videoPath); os.IsNotExist(statErr) {
-		alt := fmt.Sprintf("%s/video.webm", workDir)
-		if _, altErr := os.Stat(alt); altErr == nil {
-			videoPath = alt
-		} else {
-			err := fmt.Errorf("video file missing after download")
-			a.recordTaskError(taskID, err, "No downloaded video found for audio extraction")
-			return nil, err
-		}
+	videoPath := filepath.Join(workDir, result.File)
+	if _, statErr := os.Stat(videoPath); statErr != nil {
+		err := fmt.Errorf("%s file missing after download", mode)
+		a.recordTaskError(taskID, err, "No downloaded media found for audio extraction", "file", result.File)
+		return nil, err
 	}
+	a.recordFormat(ctx, taskID, preset, result, videoPath)
 
 	audioPath := fmt.Sprintf("%s/audio.aac", workDir)
-	if err := a.downloader.ExtractAudio(videoPath, audioPath); err != nil {
-		a.recordTaskError(taskID, err, "Failed to extract audio")
-		return nil, err
+	if err := a.downloader.ExtractAudio(ctx, videoPath, audioPath,
+		a.stageProgress(taskID, types.TaskStatusDownloading, types.ProgressVideoDownloaded, types.ProgressAudioExtracted)); err != nil {
+		return nil, a.stageError(ctx, taskID, err, "Failed to extract audio")
 	}
 
-	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDownloading, ProgressAudioExtracted); err != nil {
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDownloading, types.ProgressAudioExtracted); err != nil {
 		return nil, err
 	}
 
+	outputs := []string{filepath.Base(videoPath), "audio.aac"}
+	if a.fetchCaptions(ctx, updatedTask, info) {
+		outputs = append(outputs, services.CaptionsFile)
+	}
+	if err := services.SaveChapters(workDir, services.ExtractChapters(info)); err != nil {
+		a.logger.Warn("Failed to save chapters", "taskId", taskID, "error", err)
+	} else {
+		outputs = append(outputs, services.ChaptersFile)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	a.completeStage(updatedTask, types.TaskStatusDownloading, params, nil, outputs, "yt-dlp", "ffmpeg")
+
 	a.logger.Info("Download stage completed", "taskId", taskID, "workDir", workDir)
 	return a.taskManager.GetTask(taskID)
 }
 
+// videoInfo returns the task's video metadata from the info.json saved in
+// its directory. The metadata is fetched, and saved, when the file is
+// missing or refresh is set.
+func (a *App) videoInfo(ctx context.Context, task *types.Task, refresh bool) (*services.VideoInfo, error) {
+	if !refresh && task.WorkDir != "" {
+		info, err := a.downloader.LoadVideoInfo(task.WorkDir, task.URL)
+		if err == nil {
+			return info, nil
+		}
+		if !os.IsNotExist(err) {
+			a.logger.Warn("Failed to read saved video info, fetching it again", "taskId", task.ID, "error", err)
+		}
+	}
+
+	var info *services.VideoInfo
+	err := a.withRetry(ctx, task.WorkDir, "download", func() error {
+		var infoErr error
+		if refresh {
+			info, infoErr = a.downloader.RefreshVideoInfo(ctx, task.URL)
+		} else {
+			info, infoErr = a.downloader.GetVideoInfo(ctx, task.URL)
+		}
+		return infoErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	if task.WorkDir != "" {
+		if err := a.downloader.SaveVideoInfo(task.WorkDir, info); err != nil {
+			a.logger.Warn("Failed to save video info", "taskId", task.ID, "error", err)
+		}
+	}
+	return info, nil
+}
+
+// RefreshTaskMetadata fetches the video's metadata again, replacing the
+// saved info.json, and updates the task's title, channel, duration and
+// thumbnail. Later stages read the refreshed file.
+func (a *App) RefreshTaskMetadata(taskID string) (*types.Task, error) {
+	task, err := a.ensureTaskLoaded(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Platform == string(platform.Local) {
+		return nil, fmt.Errorf("task %s was imported from a local file and has no online metadata", taskID)
+	}
+
+	a.logger.Info("Refreshing task metadata", "taskId", taskID, "url", task.URL)
+	info, err := a.videoInfo(a.ctx, task, true)
+	if err != nil {
+		a.logger.Error("Failed to refresh metadata", "taskId", taskID, "error", err)
+		return nil, err
+	}
+	if err := a.taskManager.UpdateTaskMetadata(taskID, a.videoMetadata(task.URL, info)); err != nil {
+		a.logger.Error("Failed to update metadata", "taskId", taskID, "error", err)
+		return nil, err
+	}
+	return a.taskManager.GetTask(taskID)
+}
+
+// downloadPreset returns the format preset the download stage uses for the
+// task, chosen by services.SelectFormatPreset from the task and settings
+func (a *App) downloadPreset(task *types.Task) types.FormatPreset {
+	// A direct link to an audio file has no video to download
+	if task.Platform == string(platform.Generic) && platform.IsDirectAudio(task.URL) {
+		preset, _ := services.FindFormatPreset(nil, services.AudioFormatPresetName)
+		return preset
+	}
+	return services.SelectFormatPreset(a.settings.FormatPresets, a.settings.DefaultFormatPreset, a.settings.DownloadMode, task.FormatPreset, task.DownloadMode)
+}
+
+// recordFormat stores the preset, format and resolution of a finished
+// download on the task. The resolution is read with ffprobe; failing to read
+// it only leaves it out.
+func (a *App) recordFormat(ctx context.Context, taskID string, preset types.FormatPreset, result *services.DownloadResult, path string) {
+	format := types.DownloadedFormat{
+		Preset: preset.Name,
+		Format: result.Format,
+		File:   result.File,
+	}
+	if !preset.AudioOnly {
+		if media, err := a.downloader.ProbeMedia(ctx, path); err != nil {
+			a.logger.Warn("Failed to read resolution of downloaded video", "taskId", taskID, "error", err)
+		} else {
+			format.Width, format.Height = media.Width, media.Height
+		}
+	}
+	if _, err := a.taskManager.SetTaskFormat(taskID, format); err != nil {
+		a.logger.Warn("Failed to record downloaded format", "taskId", taskID, "error", err)
+	}
+}
+
+// SetTaskDownloadMode overrides the download mode from settings for one task.
+// An empty mode makes the task follow the settings again. It applies the
+// next time the download stage runs.
+func (a *App) SetTaskDownloadMode(taskID string, mode string) (*types.Task, error) {
+	switch types.DownloadMode(mode) {
+	case "", types.DownloadModeVideo, types.DownloadModeAudio:
+	default:
+		return nil, fmt.Errorf("unknown download mode: %s", mode)
+	}
+	if _, err := a.ensureTaskLoaded(taskID); err != nil {
+		return nil, err
+	}
+	return a.taskManager.SetTaskDownloadMode(taskID, types.DownloadMode(mode))
+}
+
+// fetchCaptions saves the video's caption track in the task's source
+// language as captions.vtt, if it has one, so transcription can skip ASR.
+// Failures are logged and leave the task to be transcribed with yap.
+func (a *App) fetchCaptions(ctx context.Context, task *types.Task, info *services.VideoInfo) bool {
+	captionsPath := filepath.Join(task.WorkDir, services.CaptionsFile)
+	if err := os.Remove(captionsPath); err != nil && !os.IsNotExist(err) {
+		a.logger.Warn("Failed to remove stale captions", "taskId", task.ID, "error", err)
+	}
+
+	var captions *types.Captions
+	if !task.ForceASR {
+		captions = services.SelectCaptions(info, task.SourceLang)
+	}
+	if captions != nil {
+		if err := a.downloader.DownloadCaptions(ctx, task.URL, task.WorkDir, captions); err != nil {
+			a.logger.Warn("Failed to download captions, falling back to ASR", "taskId", task.ID, "lang", captions.Lang, "error", err)
+			_ = a.storage.SaveLog(task.WorkDir, "download", fmt.Sprintf("Captions unavailable, falling back to ASR: %v", err))
+			captions = nil
+		}
+	}
+
+	if _, err := a.taskManager.SetTaskCaptions(task.ID, captions); err != nil {
+		a.logger.Warn("Failed to record captions", "taskId", task.ID, "error", err)
+	}
+	return captions != nil
+}
+
+// extractLocalAudio runs the download stage of an imported file, which only
+// has to extract the audio track of the file already in the task directory
+func (a *App) extractLocalAudio(ctx context.Context, task *types.Task, params map[string]string) (*types.Task, error) {
+	if task.SourceFile == "" {
+		err := fmt.Errorf("imported file is not recorded for task %s", task.ID)
+		a.recordTaskError(task.ID, err, "Imported file missing")
+		return nil, err
+	}
+	sourcePath := filepath.Join(task.WorkDir, task.SourceFile)
+	if _, err := os.Stat(sourcePath); err != nil {
+		a.recordTaskError(task.ID, err, "Imported file missing", "path", sourcePath)
+		return nil, err
+	}
+
+	a.logger.Info("Extracting audio of imported file", "taskId", task.ID, "path", sourcePath)
+
+	if err := a.taskManager.UpdateTaskStatus(task.ID, types.TaskStatusDownloading, types.ProgressVideoDownloaded); err != nil {
+		return nil, err
+	}
+
+	audioPath := filepath.Join(task.WorkDir, "audio.aac")
+	if err := a.downloader.ExtractAudio(ctx, sourcePath, audioPath,
+		a.stageProgress(task.ID, types.TaskStatusDownloading, types.ProgressVideoDownloaded, types.ProgressAudioExtracted)); err != nil {
+		return nil, a.stageError(ctx, task.ID, err, "Failed to extract audio")
+	}
+
+	if err := a.taskManager.UpdateTaskStatus(task.ID, types.TaskStatusDownloading, types.ProgressAudioExtracted); err != nil {
+		return nil, err
+	}
+
+	a.completeStage(task, types.TaskStatusDownloading, params, []string{task.SourceFile},
+		[]string{"audio.aac"}, "ffmpeg")
+
+	a.logger.Info("Audio extracted from imported file", "taskId", task.ID)
+	return a.taskManager.GetTask(task.ID)
+}
+
 // DownloadTask executes metadata fetching, workspace preparation, and media download
 func (a *App) DownloadTask(taskID string) (*types.Task, error) {
 	// Acquire task lock to prevent concurrent operations
-	if err := a.taskManager.LockTask(taskID); err != nil {
+	ctx, err := a.taskManager.LockTask(taskID)
+	if err != nil {
 		a.logger.Warn("Task is already being processed", "taskId", taskID, "error", err)
 		return nil, err
 	}
 	defer a.taskManager.UnlockTask(taskID)
+	defer a.taskManager.Dequeue(taskID)
 
-	return a.downloadTaskInternal(taskID)
+	return a.runStage(ctx, taskID, types.TaskStatusDownloading, a.downloadTaskInternal, true)
 }
 
 // transcribeTaskInternal is the internal implementation without lock acquisition
-func (a *App) transcribeTaskInternal(taskID string) (*types.Task, error) {
+func (a *App) transcribeTaskInternal(ctx context.Context, taskID string, force bool) (*types.Task, error) {
 	task, err := a.ensureTaskLoaded(taskID)
 	if err != nil {
 		return nil, err
@@ -505,17 +1246,8 @@ func (a *App) transcribeTaskInternal(taskID string) (*types.Task, error) {
 		return nil, fmt.Errorf("task %s has no working directory", taskID)
 	}
 
-	switch task.Status {
-	case types.TaskStatusTranscribing:
-		return nil, fmt.Errorf("transcription already in progress")
-	case types.TaskStatusPending:
-		return nil, fmt.Errorf("download stage must complete before transcription")
-	case types.TaskStatusSummarizing:
-		return nil, fmt.Errorf("cannot transcribe while summarization is running")
-	}
-
-	if task.Progress < 60 {
-		return nil, fmt.Errorf("download stage must complete before transcription")
+	if err := types.ValidateTransition(task, types.TaskStatusTranscribing); err != nil {
+		return nil, err
 	}
 
 	audioPath := fmt.Sprintf("%s/audio.aac", task.WorkDir)
@@ -527,47 +1259,102 @@ func (a *App) transcribeTaskInternal(taskID string) (*types.Task, error) {
 		return nil, err
 	}
 
-	if err := a.taskManager.BeginStage(
-		taskID,
-		types.TaskStatusTranscribing,
-		ProgressTranscribeStart,
-		types.TaskStatusDownloading,
-		types.TaskStatusFailed,
-		types.TaskStatusDone,
-	); err != nil {
+	if err := a.taskManager.BeginStage(taskID, types.TaskStatusTranscribing, types.ProgressTranscribeStart); err != nil {
 		a.logger.Warn("Transcription stage rejected", "taskId", taskID, "error", err)
 		return nil, err
 	}
 
+	if !task.ForceASR && services.CaptionsMatch(task.Captions, task.SourceLang) &&
+		fileExists(filepath.Join(task.WorkDir, services.CaptionsFile)) {
+		return a.transcribeFromCaptions(ctx, task, force)
+	}
+
+	params := a.yapRunner.TranscribeParams(task.SourceLang)
+	if !force && a.stageIsCurrent(task, types.TaskStatusTranscribing, params, "audio.aac") {
+		return a.skipStage(task, types.TaskStatusTranscribing, types.ProgressTranscribeComplete, "asr")
+	}
+
 	a.logger.Info("Transcription stage started", "taskId", taskID, "lang", task.SourceLang)
 
-	if err := a.yapRunner.Transcribe(audioPath, task.WorkDir, task.SourceLang); err != nil {
-		a.recordTaskError(taskID, err, "Failed to transcribe", "lang", task.SourceLang)
-		return nil, err
+	if err := a.withRetry(ctx, task.WorkDir, "asr", func() error {
+		return a.yapRunner.Transcribe(ctx, audioPath, task.WorkDir, task.SourceLang,
+			a.stageProgress(taskID, types.TaskStatusTranscribing, types.ProgressTranscribeStart, types.ProgressTranscribeComplete))
+	}); err != nil {
+		return nil, a.stageError(ctx, taskID, err, "Failed to transcribe", "lang", task.SourceLang)
 	}
 
-	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusTranscribing, ProgressTranscribeComplete); err != nil {
+	if _, err := a.taskManager.SetTranscriptSource(taskID, types.TranscriptSourceASR); err != nil {
+		a.logger.Warn("Failed to record transcript source", "taskId", taskID, "error", err)
+	}
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusTranscribing, types.ProgressTranscribeComplete); err != nil {
 		return nil, err
 	}
 
+	a.completeStage(task, types.TaskStatusTranscribing, params, []string{"audio.aac"},
+		[]string{fmt.Sprintf("subs_%s.srt", task.SourceLang)}, "yap")
+
 	a.logger.Info("Transcription stage completed", "taskId", taskID)
 	return a.taskManager.GetTask(taskID)
 }
 
+// transcribeFromCaptions runs the transcription stage by converting the
+// downloaded caption track instead of running ASR
+func (a *App) transcribeFromCaptions(ctx context.Context, task *types.Task, force bool) (*types.Task, error) {
+	params := map[string]string{
+		"language": task.SourceLang,
+		"source":   string(task.Captions.Source),
+		"captions": task.Captions.Lang,
+	}
+	if !force && a.stageIsCurrent(task, types.TaskStatusTranscribing, params, services.CaptionsFile) {
+		return a.skipStage(task, types.TaskStatusTranscribing, types.ProgressTranscribeComplete, "asr")
+	}
+
+	a.logger.Info("Transcription stage started from captions", "taskId", task.ID, "lang", task.SourceLang, "source", task.Captions.Source)
+
+	srtFile := fmt.Sprintf("subs_%s.srt", task.SourceLang)
+	if err := services.ConvertCaptionsFile(filepath.Join(task.WorkDir, services.CaptionsFile), filepath.Join(task.WorkDir, srtFile)); err != nil {
+		return nil, a.stageError(ctx, task.ID, err, "Failed to convert captions", "lang", task.Captions.Lang)
+	}
+	_ = a.storage.SaveLog(task.WorkDir, "asr", fmt.Sprintf("Transcript taken from %s captions (%s); ASR skipped", task.Captions.Source, task.Captions.Lang))
+
+	if _, err := a.taskManager.SetTranscriptSource(task.ID, task.Captions.Source); err != nil {
+		a.logger.Warn("Failed to record transcript source", "taskId", task.ID, "error", err)
+	}
+	if err := a.taskManager.UpdateTaskStatus(task.ID, types.TaskStatusTranscribing, types.ProgressTranscribeComplete); err != nil {
+		return nil, err
+	}
+
+	a.completeStage(task, types.TaskStatusTranscribing, params, []string{services.CaptionsFile}, []string{srtFile})
+
+	a.logger.Info("Transcription stage completed", "taskId", task.ID, "source", task.Captions.Source)
+	return a.taskManager.GetTask(task.ID)
+}
+
+// SetTaskForceASR sets whether the task is transcribed with yap even when the
+// video has captions. It applies the next time the transcription stage runs.
+func (a *App) SetTaskForceASR(taskID string, force bool) (*types.Task, error) {
+	if _, err := a.ensureTaskLoaded(taskID); err != nil {
+		return nil, err
+	}
+	return a.taskManager.SetTaskForceASR(taskID, force)
+}
+
 // TranscribeTask triggers Yap transcription using the prepared audio file
 func (a *App) TranscribeTask(taskID string) (*types.Task, error) {
 	// Acquire task lock to prevent concurrent operations
-	if err := a.taskManager.LockTask(taskID); err != nil {
+	ctx, err := a.taskManager.LockTask(taskID)
+	if err != nil {
 		a.logger.Warn("Task is already being processed", "taskId", taskID, "error", err)
 		return nil, err
 	}
 	defer a.taskManager.UnlockTask(taskID)
+	defer a.taskManager.Dequeue(taskID)
 
-	return a.transcribeTaskInternal(taskID)
+	return a.runStage(ctx, taskID, types.TaskStatusTranscribing, a.transcribeTaskInternal, true)
 }
 
 // summarizeTaskInternal is the internal implementation without lock acquisition
-func (a *App) summarizeTaskInternal(taskID string) (*types.Task, error) {
+func (a *App) summarizeTaskInternal(ctx context.Context, taskID string, force bool) (*types.Task, error) {
 	task, err := a.ensureTaskLoaded(taskID)
 	if err != nil {
 		return nil, err
@@ -577,15 +1364,8 @@ func (a *App) summarizeTaskInternal(taskID string) (*types.Task, error) {
 		return nil, fmt.Errorf("task %s has no working directory", taskID)
 	}
 
-	switch task.Status {
-	case types.TaskStatusSummarizing:
-		return nil, fmt.Errorf("summarization already in progress")
-	case types.TaskStatusPending, types.TaskStatusDownloading:
-		return nil, fmt.Errorf("run download and transcription stages before summarizing")
-	case types.TaskStatusTranscribing:
-		if task.Progress < 80 {
-			return nil, fmt.Errorf("transcription stage must complete before summarization")
-		}
+	if err := types.ValidateTransition(task, types.TaskStatusSummarizing); err != nil {
+		return nil, err
 	}
 
 	srtPath := fmt.Sprintf("%s/subs_%s.srt", task.WorkDir, task.SourceLang)
@@ -598,34 +1378,57 @@ func (a *App) summarizeTaskInternal(taskID string) (*types.Task, error) {
 		return nil, err
 	}
 
-	if err := a.taskManager.BeginStage(
-		taskID,
-		types.TaskStatusSummarizing,
-		ProgressSummarizeStart,
-		types.TaskStatusTranscribing,
-		types.TaskStatusFailed,
-		types.TaskStatusDone,
-	); err != nil {
+	if err := a.taskManager.BeginStage(taskID, types.TaskStatusSummarizing, types.ProgressSummarizeStart); err != nil {
 		a.logger.Warn("Summarization stage rejected", "taskId", taskID, "error", err)
 		return nil, err
 	}
 
+	srtName := filepath.Base(srtPath)
+	params := a.summaryParams()
+	// Chapters shape the summary, so they are an input when the video has any
+	inputs := []string{srtName}
+	chapters := a.ensureChapters(task)
+	if len(chapters) > 0 {
+		inputs = append(inputs, services.ChaptersFile)
+	}
+	if !force && a.stageIsCurrent(task, types.TaskStatusSummarizing, params, inputs...) {
+		if _, err := a.skipStage(task, types.TaskStatusSummarizing, types.ProgressSummarizeComplete, "summarize"); err != nil {
+			return nil, err
+		}
+		if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDone, types.ProgressTaskComplete); err != nil {
+			return nil, err
+		}
+		return a.taskManager.GetTask(taskID)
+	}
+
 	a.logger.Info("Summarization stage started", "taskId", taskID, "provider", a.settings.APIProvider)
 
-	sumBytes, summarizeErr := a.summarizer.SummarizeStructured(
-		a.ctx,
-		a.settings.APIKey,
-		string(srtBytes),
-		a.settings.SummaryLength,
-		a.settings.SummaryLanguage,
-		a.settings.Temperature,
-		a.settings.MaxTokens,
-	)
+	var sumBytes []byte
+	summarizeErr := a.withRetry(ctx, task.WorkDir, "summarize", func() error {
+		var err error
+		sumBytes, err = a.summarizer.SummarizeStructured(
+			ctx,
+			a.settings.APIKey,
+			string(srtBytes),
+			chapters,
+			a.settings.SummaryLength,
+			a.settings.SummaryLanguage,
+			a.settings.Temperature,
+			a.settings.MaxTokens,
+		)
+		return err
+	})
+
+	if ctx.Err() != nil {
+		a.logger.Info("Summarization interrupted by cancellation", "taskId", taskID)
+		return nil, ctx.Err()
+	}
 
 	summaryPath := fmt.Sprintf("%s/summary_structured.json", task.WorkDir)
 	if summarizeErr != nil {
 		a.logger.Error("Summarization failed", "taskId", taskID, "error", summarizeErr)
 		_ = a.storage.SaveLog(task.WorkDir, "summarize", fmt.Sprintf("Summary generation failed: %v", summarizeErr))
+		_ = a.taskManager.FinishStage(taskID, types.TaskStatusSummarizing, types.StageOutcomeFailed, nil, summarizeErr)
 		placeholder := []byte(`{"type":"structured","content":{"keyPoints":[],"mainTopic":"","conclusion":"","tags":[]}}`)
 		if writeErr := os.WriteFile(summaryPath, placeholder, 0644); writeErr != nil {
 			a.logger.Error("Failed to write placeholder summary", "taskId", taskID, "error", writeErr)
@@ -634,18 +1437,21 @@ func (a *App) summarizeTaskInternal(taskID string) (*types.Task, error) {
 		if writeErr := os.WriteFile(summaryPath, sumBytes, 0644); writeErr != nil {
 			a.logger.Error("Failed to write summary", "taskId", taskID, "error", writeErr)
 			_ = a.storage.SaveLog(task.WorkDir, "summarize", fmt.Sprintf("Failed to write summary: %v", writeErr))
+			_ = a.taskManager.FinishStage(taskID, types.TaskStatusSummarizing, types.StageOutcomeFailed, nil, writeErr)
 		} else {
 			_ = a.storage.SaveLog(task.WorkDir, "summarize", "Summary generated via OpenRouter")
+			a.completeStage(task, types.TaskStatusSummarizing, params, inputs,
+				[]string{"summary_structured.json"})
 			a.logger.Info("Summarization complete", "taskId", taskID, "path", summaryPath)
 		}
 	}
 
-	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusSummarizing, ProgressSummarizeComplete); err != nil {
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusSummarizing, types.ProgressSummarizeComplete); err != nil {
 		return nil, err
 	}
 
 	if summarizeErr == nil {
-		if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDone, ProgressTaskComplete); err != nil {
+		if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDone, types.ProgressTaskComplete); err != nil {
 			return nil, err
 		}
 	}
@@ -661,13 +1467,28 @@ func (a *App) summarizeTaskInternal(taskID string) (*types.Task, error) {
 // SummarizeTask generates video summaries via the configured LLM client
 func (a *App) SummarizeTask(taskID string) (*types.Task, error) {
 	// Acquire task lock to prevent concurrent operations
-	if err := a.taskManager.LockTask(taskID); err != nil {
+	ctx, err := a.taskManager.LockTask(taskID)
+	if err != nil {
 		a.logger.Warn("Task is already being processed", "taskId", taskID, "error", err)
 		return nil, err
 	}
 	defer a.taskManager.UnlockTask(taskID)
+	defer a.taskManager.Dequeue(taskID)
 
-	return a.summarizeTaskInternal(taskID)
+	return a.runStage(ctx, taskID, types.TaskStatusSummarizing, a.summarizeTaskInternal, true)
+}
+
+// GetTaskTimeline returns the history of stage attempts for the task, oldest
+// first
+func (a *App) GetTaskTimeline(taskID string) ([]types.StageRecord, error) {
+	task, err := a.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Stages == nil {
+		return []types.StageRecord{}, nil
+	}
+	return task.Stages, nil
 }
 
 // GetAllTasks returns all processed tasks
@@ -683,6 +1504,9 @@ type SubtitleEntry struct {
 	EndTime   string `json:"endTime"`
 	English   string `json:"english"`
 	Chinese   string `json:"chinese,omitempty"`
+	// Chapter is the title of the chapter the entry starts in, if the video
+	// has chapters
+	Chapter string `json:"chapter,omitempty"`
 }
 
 // GetTaskSubtitles returns parsed subtitles for a task
@@ -723,10 +1547,94 @@ func (a *App) GetTaskSubtitles(taskID string) ([]SubtitleEntry, error) {
 
 	// Parse SRT format
 	entries := parseSRT(string(content))
+	annotateChapters(entries, a.taskChapters(task))
 	a.logger.Info("Parsed subtitles", "taskId", taskID, "entries", len(entries))
 	return entries, nil
 }
 
+// annotateChapters sets the chapter of each entry from its start time
+func annotateChapters(entries []SubtitleEntry, chapters []types.Chapter) {
+	if len(chapters) == 0 {
+		return
+	}
+	for i := range entries {
+		start, ok := services.SRTSeconds(entries[i].StartTime)
+		if !ok {
+			continue
+		}
+		if index := services.ChapterAt(chapters, start); index >= 0 {
+			entries[i].Chapter = chapters[index].Title
+		}
+	}
+}
+
+// GetTaskChapters returns the chapters of the task's video, from yt-dlp or
+// the video description. Videos without chapters yield an empty list.
+func (a *App) GetTaskChapters(taskID string) ([]types.Chapter, error) {
+	task, err := a.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	chapters := a.taskChapters(task)
+	if chapters == nil {
+		return []types.Chapter{}, nil
+	}
+	return chapters, nil
+}
+
+// taskChapters reads the task's chapters.json. Tasks downloaded before
+// chapters were saved have theirs read from the saved info.json; the file
+// itself is only written by ensureChapters. Failures are logged and leave the
+// task without chapters.
+func (a *App) taskChapters(task *types.Task) []types.Chapter {
+	if task.WorkDir == "" || task.Platform == string(platform.Local) {
+		return nil
+	}
+	chapters, err := services.LoadChapters(task.WorkDir)
+	if err == nil {
+		return chapters
+	}
+	if !os.IsNotExist(err) {
+		a.logger.Warn("Failed to read chapters", "taskId", task.ID, "error", err)
+		return nil
+	}
+	chapters, _ = a.savedInfoChapters(task)
+	return chapters
+}
+
+// ensureChapters returns the task's chapters like taskChapters, saving
+// chapters.json first for a task downloaded before chapters were saved. It
+// is called by the stages and at startup, never by read-only bindings.
+func (a *App) ensureChapters(task *types.Task) []types.Chapter {
+	if task.WorkDir == "" || task.Platform == string(platform.Local) {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(task.WorkDir, services.ChaptersFile)); !os.IsNotExist(err) {
+		return a.taskChapters(task)
+	}
+	chapters, ok := a.savedInfoChapters(task)
+	if !ok {
+		return nil
+	}
+	if err := services.SaveChapters(task.WorkDir, chapters); err != nil {
+		a.logger.Warn("Failed to save chapters", "taskId", task.ID, "error", err)
+	}
+	return chapters
+}
+
+// savedInfoChapters extracts the chapters from the task's saved info.json,
+// reporting whether the file could be read
+func (a *App) savedInfoChapters(task *types.Task) ([]types.Chapter, bool) {
+	info, err := a.downloader.LoadVideoInfo(task.WorkDir, task.URL)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			a.logger.Warn("Failed to read saved video info for chapters", "taskId", task.ID, "error", err)
+		}
+		return nil, false
+	}
+	return services.ExtractChapters(info), true
+}
+
 // GetTaskSubtitlesForLang returns parsed subtitles for a task for the specified language code
 // GetTaskSubtitlesForLang and RegenerateTranscript were removed to keep a single-language flow.
 
@@ -802,6 +1710,10 @@ func parseSRT(content string) []SubtitleEntry {
 // DeleteTask deletes a task and its associated files
 func (a *App) DeleteTask(taskID string) error {
 	a.logger.Info("Deleting task", "taskId", taskID)
+	if err := a.taskManager.DeleteTask(taskID); err != nil {
+		a.logger.Warn("Delete rejected", "taskId", taskID, "error", err)
+		return err
+	}
 	err := a.storage.DeleteTask(taskID)
 	if err != nil {
 		a.logger.Error("Failed to delete task", "taskId", taskID, "error", err)
@@ -831,43 +1743,255 @@ func (a *App) recordTaskError(taskID string, err error, message string, attrs ..
 	fields := append([]any{"taskId", taskID, "error", err}, attrs...)
 	a.logger.Error(message, fields...)
 
-	if setErr := a.taskManager.SetTaskError(taskID, fmt.Sprintf("%s: %v", message, err)); setErr != nil {
+	if setErr := a.taskManager.SetTaskError(taskID, services.ErrorCodeOf(err), fmt.Sprintf("%s: %v", message, err)); setErr != nil {
 		a.logger.Error("Failed to record task error", "taskId", taskID, "error", setErr)
 	}
 }
 
+// withRetry runs fn, retrying transient failures with exponential backoff.
+// Each retry is noted in the task's log of the given type.
+func (a *App) withRetry(ctx context.Context, workDir, logType string, fn func() error) error {
+	config := services.DefaultRetryConfig
+	config.OnRetry = func(attempt int, err error, delay time.Duration) {
+		if workDir == "" {
+			return
+		}
+		_ = a.storage.SaveLog(workDir, logType, fmt.Sprintf("Attempt %d failed (%s), retrying in %s: %v", attempt, services.ErrorCodeOf(err), delay, err))
+	}
+	return services.WithRetry(ctx, config, fn)
+}
+
+// stageProgress maps progress reported by a tool onto the [from, to] slice of
+// the task's overall progress reserved for that step of the stage
+func (a *App) stageProgress(taskID string, stage types.TaskStatus, from, to int) services.ProgressFunc {
+	return func(update services.ProgressUpdate) {
+		progress := from + int(update.Fraction*float64(to-from))
+		var transfer *types.TransferStats
+		if update.DownloadedBytes > 0 || update.TotalBytes > 0 {
+			transfer = &types.TransferStats{
+				DownloadedBytes: update.DownloadedBytes,
+				TotalBytes:      update.TotalBytes,
+				Speed:           update.Speed,
+				ETA:             int(update.ETA.Seconds()),
+			}
+		}
+		if err := a.taskManager.UpdateTaskProgress(taskID, stage, progress, transfer); err != nil && !errors.Is(err, services.ErrTaskCancelled) {
+			a.logger.Warn("Failed to record task progress", "taskId", taskID, "error", err)
+		}
+	}
+}
+
+// stageFunc is the lock-free implementation of a pipeline stage. Unless force
+// is set, a stage whose checkpoint is still current is skipped.
+type stageFunc func(ctx context.Context, taskID string, force bool) (*types.Task, error)
+
+// runStage waits for a free worker slot for the stage, then runs it. If the
+// run is cancelled while the stage is executing, its partial outputs are
+// removed.
+func (a *App) runStage(ctx context.Context, taskID string, stage types.TaskStatus, run stageFunc, force bool) (*types.Task, error) {
+	if _, err := a.taskManager.GetTask(taskID); err != nil {
+		if _, err := a.ensureTaskLoaded(taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := a.taskManager.WaitForStage(ctx, taskID, stage)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Warn("Failed to acquire stage slot", "taskId", taskID, "stage", stage, "error", err)
+		}
+		return nil, err
+	}
+	defer release()
+
+	task, err := run(ctx, taskID, force)
+	if err != nil {
+		a.cleanupIfCancelled(ctx, taskID, stage)
+	}
+	return task, err
+}
+
+// stageIsCurrent reports whether the stage already ran with the same
+// parameters on the same input files and its outputs are untouched
+func (a *App) stageIsCurrent(task *types.Task, stage types.TaskStatus, params map[string]string, inputs ...string) bool {
+	if task.WorkDir == "" {
+		return false
+	}
+	checkpoints, err := a.storage.LoadCheckpoints(task.WorkDir)
+	if err != nil {
+		a.logger.Warn("Failed to load checkpoints", "taskId", task.ID, "error", err)
+		return false
+	}
+	return checkpoints[stage].IsCurrent(task.WorkDir, params, inputs)
+}
+
+// skipStage marks a stage with a current checkpoint as completed without
+// running it
+func (a *App) skipStage(task *types.Task, stage types.TaskStatus, progress int, logType string) (*types.Task, error) {
+	a.logger.Info("Stage checkpoint is current; skipping", "taskId", task.ID, "stage", stage)
+	_ = a.storage.SaveLog(task.WorkDir, logType, fmt.Sprintf("Skipped %s: checkpoint is current", stage))
+	if err := a.taskManager.FinishStage(task.ID, stage, types.StageOutcomeSkipped, nil, nil); err != nil {
+		a.logger.Warn("Failed to record skipped stage", "taskId", task.ID, "stage", stage, "error", err)
+	}
+
+	if err := a.taskManager.UpdateTaskStatus(task.ID, stage, progress); err != nil {
+		return nil, err
+	}
+	return a.taskManager.GetTask(task.ID)
+}
+
+// completeStage records a completed stage in the task timeline and saves its
+// checkpoint. Failures are only logged: without a checkpoint the stage simply
+// runs again next time.
+func (a *App) completeStage(task *types.Task, stage types.TaskStatus, params map[string]string, inputs, outputs []string, tools ...string) {
+	versions := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		versions[tool] = a.depChecker.ToolVersion(tool)
+	}
+
+	if err := a.taskManager.FinishStage(task.ID, stage, types.StageOutcomeSucceeded, versions, nil); err != nil {
+		a.logger.Warn("Failed to record stage completion", "taskId", task.ID, "stage", stage, "error", err)
+	}
+
+	cp, err := services.NewCheckpoint(task.WorkDir, stage, params, inputs, outputs, versions)
+	if err == nil {
+		err = a.storage.SaveCheckpoint(task.WorkDir, cp)
+	}
+	if err != nil {
+		a.logger.Warn("Failed to save stage checkpoint", "taskId", task.ID, "stage", stage, "error", err)
+	}
+}
+
+// summaryParams returns the settings that determine the generated summary
+func (a *App) summaryParams() map[string]string {
+	return map[string]string{
+		"model":       services.SummaryModel,
+		"length":      a.settings.SummaryLength,
+		"language":    a.settings.SummaryLanguage,
+		"temperature": strconv.FormatFloat(a.settings.Temperature, 'f', -1, 64),
+		"maxTokens":   strconv.Itoa(a.settings.MaxTokens),
+	}
+}
+
+// stageError records a stage failure on the task and returns it. When the run
+// was cancelled the failure is only the symptom of the killed subprocess, so
+// the cancellation is returned instead and the task keeps its cancelled state.
+func (a *App) stageError(ctx context.Context, taskID string, err error, message string, attrs ...any) error {
+	if ctx.Err() != nil {
+		a.logger.Info("Stage interrupted by cancellation", "taskId", taskID, "stage", message)
+		return ctx.Err()
+	}
+	a.recordTaskError(taskID, err, message, attrs...)
+	return err
+}
+
+// cleanupIfCancelled removes files left half-written by the given stage when
+// the run was cancelled. Completed artifacts from earlier stages are kept.
+func (a *App) cleanupIfCancelled(ctx context.Context, taskID string, stage types.TaskStatus) {
+	if ctx.Err() == nil {
+		return
+	}
+
+	task, err := a.taskManager.GetTask(taskID)
+	if err != nil || task.WorkDir == "" {
+		return
+	}
+
+	var outputs []string
+	switch stage {
+	case types.TaskStatusDownloading:
+		outputs = append(outputs, "audio.aac")
+	case types.TaskStatusTranscribing:
+		outputs = append(outputs, fmt.Sprintf("subs_%s.srt", task.SourceLang))
+	}
+
+	// A paused download resumes from its partial files, but stage outputs
+	// may be truncated and are always written again
+	if errors.Is(context.Cause(ctx), services.ErrTaskPaused) {
+		for _, name := range outputs {
+			path := filepath.Join(task.WorkDir, name)
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				a.logger.Warn("Failed to remove partial output", "taskId", taskID, "path", path, "error", err)
+			}
+		}
+		return
+	}
+
+	if err := a.storage.RemovePartialFiles(task.WorkDir, outputs...); err != nil {
+		a.logger.Warn("Failed to remove partial files", "taskId", taskID, "error", err)
+		return
+	}
+	a.logger.Info("Removed partial files of cancelled stage", "taskId", taskID, "stage", stage)
+}
+
+// pipelineStages lists the processing stages in execution order
+var pipelineStages = []types.TaskStatus{
+	types.TaskStatusDownloading,
+	types.TaskStatusTranscribing,
+	types.TaskStatusSummarizing,
+}
+
 // processTask handles the actual task processing
 func (a *App) processTask(taskID string) {
+	a.processTaskFrom(taskID, types.TaskStatusDownloading)
+}
+
+// processTaskFrom runs the pipeline starting at the given stage, skipping the
+// stages before it whose results are already on disk.
+func (a *App) processTaskFrom(taskID string, from types.TaskStatus) {
+	if !slices.Contains(pipelineStages, from) {
+		a.logger.Error("Unknown pipeline stage", "taskId", taskID, "stage", from)
+		return
+	}
+
 	// Acquire task lock to prevent concurrent operations
-	if err := a.taskManager.LockTask(taskID); err != nil {
+	ctx, err := a.taskManager.LockTask(taskID)
+	if err != nil {
 		a.logger.Warn("Task is already being processed", "taskId", taskID, "error", err)
 		return
 	}
 
+	a.runPipeline(ctx, taskID, from, false)
+}
+
+// runPipeline runs every stage from the given one onwards. The caller must
+// hold the task lock, which runPipeline releases when it returns. With force
+// set, stages run even if their checkpoints are current.
+func (a *App) runPipeline(ctx context.Context, taskID string, from types.TaskStatus, force bool) {
 	locked := true
 	defer func() {
 		if locked {
 			a.taskManager.UnlockTask(taskID)
 		}
 	}()
+	defer a.taskManager.Dequeue(taskID)
 
-	a.logger.Info("Processing task started", "taskId", taskID)
-
-	if _, err := a.downloadTaskInternal(taskID); err != nil {
-		a.logger.Error("Download stage failed", "taskId", taskID, "error", err)
-		return
-	}
+	a.logger.Info("Processing task started", "taskId", taskID, "from", from)
 
-	if _, err := a.transcribeTaskInternal(taskID); err != nil {
-		a.logger.Error("Transcription stage failed", "taskId", taskID, "error", err)
-		return
-	}
+	started := false
+	for _, stage := range pipelineStages {
+		if stage == from {
+			started = true
+		}
+		if !started {
+			continue
+		}
 
-	if _, err := a.summarizeTaskInternal(taskID); err != nil {
-		a.logger.Warn("Summarization stage completed with warnings", "taskId", taskID, "error", err)
+		if _, err := a.runStage(ctx, taskID, stage, a.stageRunner(stage), force); err != nil {
+			if ctx.Err() != nil {
+				a.emitReloadEvent()
+				return
+			}
+			if stage == types.TaskStatusSummarizing {
+				a.logger.Warn("Summarization stage completed with warnings", "taskId", taskID, "error", err)
+				break
+			}
+			a.logger.Error("Stage failed", "taskId", taskID, "stage", stage, "error", err)
+			return
+		}
 	}
 
-	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDone, ProgressTaskComplete); err != nil {
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusDone, types.ProgressTaskComplete); err != nil {
 		a.logger.Error("Failed to finalize task", "taskId", taskID, "error", err)
 		return
 	}
@@ -881,6 +2005,171 @@ func (a *App) processTask(taskID string) {
 	a.logger.Info("Task completed successfully", "taskId", taskID)
 }
 
+// RerunFrom runs the given stage and every stage after it again, even if
+// their results are current. Checkpoints and outputs of those stages are
+// discarded first, so for example a new transcript never sits next to a
+// summary of the old one. The task must not be processing.
+func (a *App) RerunFrom(taskID string, stage string, options types.RerunOptions) (*types.Task, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("taskID is required")
+	}
+	from := types.TaskStatus(stage)
+	if !slices.Contains(pipelineStages, from) {
+		return nil, fmt.Errorf("unknown pipeline stage: %s", stage)
+	}
+
+	switch options.DownloadMode {
+	case "", types.DownloadModeVideo, types.DownloadModeAudio:
+	default:
+		return nil, fmt.Errorf("unknown download mode: %s", options.DownloadMode)
+	}
+	if options.FormatPreset != "" {
+		if _, ok := services.FindFormatPreset(a.settings.FormatPresets, options.FormatPreset); !ok {
+			return nil, fmt.Errorf("unknown format preset: %s", options.FormatPreset)
+		}
+	}
+
+	task, err := a.ensureTaskLoaded(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.WorkDir == "" {
+		return nil, fmt.Errorf("task %s has no work directory", taskID)
+	}
+
+	ctx, err := a.taskManager.LockTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task is already being processed: %w", err)
+	}
+	started := false
+	defer func() {
+		if !started {
+			a.taskManager.UnlockTask(taskID)
+		}
+	}()
+
+	if !task.Status.IsTerminal() {
+		return nil, fmt.Errorf("cannot rerun task while it is %s", task.Status)
+	}
+
+	sourceLang := task.SourceLang
+	if options.SourceLang != "" {
+		sourceLang = options.SourceLang
+	}
+
+	// The stages before from are kept, so their outputs must be present
+	var progress int
+	switch from {
+	case types.TaskStatusTranscribing:
+		progress = types.ProgressAudioExtracted
+		if !fileExists(filepath.Join(task.WorkDir, "audio.aac")) {
+			return nil, fmt.Errorf("audio is missing; rerun from %s instead", types.TaskStatusDownloading)
+		}
+	case types.TaskStatusSummarizing:
+		progress = types.ProgressTranscribeComplete
+		if !fileExists(filepath.Join(task.WorkDir, fmt.Sprintf("subs_%s.srt", sourceLang))) {
+			return nil, fmt.Errorf("no %s transcript found; rerun from %s instead", sourceLang, types.TaskStatusTranscribing)
+		}
+	}
+
+	// The outputs to discard are named after the task as it is now, before
+	// the options below change its source language
+	stale := *task
+
+	if task.Status != types.TaskStatusDone {
+		if _, err := a.taskManager.RetryTask(taskID); err != nil {
+			return nil, err
+		}
+	}
+	if sourceLang != task.SourceLang {
+		if task, err = a.taskManager.UpdateTaskSourceLang(taskID, sourceLang); err != nil {
+			return nil, err
+		}
+	}
+	if options.DownloadMode != "" && options.DownloadMode != task.DownloadMode {
+		if task, err = a.taskManager.SetTaskDownloadMode(taskID, options.DownloadMode); err != nil {
+			return nil, err
+		}
+	}
+	if options.FormatPreset != "" && options.FormatPreset != task.FormatPreset {
+		if task, err = a.taskManager.SetTaskFormatPreset(taskID, options.FormatPreset); err != nil {
+			return nil, err
+		}
+	}
+	if options.ForceASR != nil && *options.ForceASR != task.ForceASR {
+		if task, err = a.taskManager.SetTaskForceASR(taskID, *options.ForceASR); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.taskManager.UpdateTaskStatus(taskID, types.TaskStatusPending, progress); err != nil {
+		return nil, err
+	}
+
+	a.invalidateStages(&stale, from)
+
+	queued, err := a.taskManager.Enqueue(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Rerunning task", "taskId", taskID, "from", from, "sourceLang", task.SourceLang)
+	_ = a.storage.SaveLog(task.WorkDir, "rerun", fmt.Sprintf("Rerunning from %s", from))
+
+	started = true
+	go a.runPipeline(ctx, taskID, from, true)
+	a.emitReloadEvent()
+
+	return queued, nil
+}
+
+// invalidateStages discards the checkpoints and outputs of the given stage and
+// every stage after it
+func (a *App) invalidateStages(task *types.Task, from types.TaskStatus) {
+	stages := pipelineStages[slices.Index(pipelineStages, from):]
+	if err := a.storage.RemoveCheckpoints(task.WorkDir, stages...); err != nil {
+		a.logger.Warn("Failed to remove stage checkpoints", "taskId", task.ID, "error", err)
+	}
+
+	for _, stage := range stages {
+		for _, name := range stageOutputs(task, stage) {
+			path := filepath.Join(task.WorkDir, name)
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				a.logger.Warn("Failed to remove stale stage output", "taskId", task.ID, "path", path, "error", err)
+			}
+		}
+	}
+}
+
+// stageOutputs lists the files in the task directory a stage produces
+func stageOutputs(task *types.Task, stage types.TaskStatus) []string {
+	switch stage {
+	case types.TaskStatusDownloading:
+		if task.Platform == string(platform.Local) {
+			// The imported file is the task's source, not a stage output
+			return []string{"audio.aac"}
+		}
+		return []string{"video.mp4", "video.webm", services.AudioOnlyFile, "audio.aac", services.CaptionsFile, services.ChaptersFile}
+	case types.TaskStatusTranscribing:
+		return []string{fmt.Sprintf("subs_%s.srt", task.SourceLang)}
+	case types.TaskStatusSummarizing:
+		return []string{"summary_structured.json"}
+	default:
+		return nil
+	}
+}
+
+// stageRunner returns the lock-free implementation of a pipeline stage
+func (a *App) stageRunner(stage types.TaskStatus) stageFunc {
+	switch stage {
+	case types.TaskStatusTranscribing:
+		return a.transcribeTaskInternal
+	case types.TaskStatusSummarizing:
+		return a.summarizeTaskInternal
+	default:
+		return a.downloadTaskInternal
+	}
+}
+
 // GetDebugInfo returns debug information about the environment and PATH
 func (a *App) GetDebugInfo() map[string]string {
 	pathFinder := utils.NewPathFinder()
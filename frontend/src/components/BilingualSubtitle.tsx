@@ -1,3 +1,4 @@
+import { Fragment } from 'react'
 import { Badge } from '@/components/ui/badge'
 import { FileText } from 'lucide-react'
 import { cn } from '@/lib/utils'
@@ -26,31 +27,38 @@ export default function BilingualSubtitle({
 
       {/* Subtitle Entries - Simple list */}
       <div className="space-y-3">
-        {subtitles.map((subtitle) => (
-          <div 
-            key={subtitle.index}
-            className="flex items-start gap-4 text-sm cursor-pointer hover:bg-accent/50 p-2 -mx-2 rounded transition-colors"
-            onClick={() => onTimeClick?.(subtitle.startTime)}
-          >
-            {/* Timestamp */}
-            <span className="font-mono text-muted-foreground min-w-[80px]">
-              {subtitle.timestamp}
-            </span>
+        {subtitles.map((subtitle, i) => (
+          <Fragment key={subtitle.index}>
+            {/* Chapter heading where a new chapter starts */}
+            {subtitle.chapter && subtitle.chapter !== subtitles[i - 1]?.chapter && (
+              <h4 className="pt-3 text-sm font-semibold first:pt-0">
+                {subtitle.chapter}
+              </h4>
+            )}
+            <div 
+              className="flex items-start gap-4 text-sm cursor-pointer hover:bg-accent/50 p-2 -mx-2 rounded transition-colors"
+              onClick={() => onTimeClick?.(subtitle.startTime)}
+            >
+              {/* Timestamp */}
+              <span className="font-mono text-muted-foreground min-w-[80px]">
+                {subtitle.timestamp}
+              </span>
 
-            {/* Content */}
-            <div className="flex-1 space-y-1">
-              {subtitle.english && subtitle.english.trim() !== '' && (
-                <p className="leading-relaxed">
-                  {subtitle.english}
-                </p>
-              )}
-              {subtitle.chinese && subtitle.chinese.trim() !== '' && (
-                <p className="leading-relaxed text-muted-foreground">
-                  {subtitle.chinese}
-                </p>
-              )}
+              {/* Content */}
+              <div className="flex-1 space-y-1">
+                {subtitle.english && subtitle.english.trim() !== '' && (
+                  <p className="leading-relaxed">
+                    {subtitle.english}
+                  </p>
+                )}
+                {subtitle.chinese && subtitle.chinese.trim() !== '' && (
+                  <p className="leading-relaxed text-muted-foreground">
+                    {subtitle.chinese}
+                  </p>
+                )}
+              </div>
             </div>
-          </div>
+          </Fragment>
         ))}
       </div>
 
@@ -369,7 +369,7 @@ const VideoPlayer = forwardRef<VideoPlayerHandle, VideoPlayerProps>(({ src, post
         crossOrigin="anonymous"
         playsInline
       >
-        <source src={src} type={src.endsWith('.webm') ? 'video/webm' : 'video/mp4'} />
+        <source src={src} type={src.endsWith('.webm') ? 'video/webm' : src.endsWith('.m4a') ? 'audio/mp4' : 'video/mp4'} />
         {subtitles.map((sub, index) => (
           <track
             key={index}
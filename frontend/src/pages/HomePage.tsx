@@ -1,4 +1,4 @@
-import { useState, useEffect, useCallback, useMemo, useRef } from 'react'
+import { useState, useEffect, useCallback, useMemo } from 'react'
 import { useNavigate, useLocation } from 'react-router-dom'
 import { Button } from '@/components/ui/button'
 import { Input } from '@/components/ui/input'
@@ -15,8 +15,9 @@ import {
   AlertDialogTitle,
 } from '@/components/ui/alert-dialog'
 import { Clock, Plus, Trash2, RefreshCcw, Loader2 } from 'lucide-react'
-import { GetAllTasks, DeleteTask, ListActiveTasks, RetryTask } from '../../wailsjs/go/main/App'
+import { GetAllTasks, DeleteTask, ListActiveTasks, RetryTask, PauseTask, ResumeTask } from '../../wailsjs/go/main/App'
 import { EventsOn } from '../../wailsjs/runtime/runtime'
+import { types } from '../../wailsjs/go/models'
 
 export default function HomePage() {
   const navigate = useNavigate()
@@ -30,13 +31,12 @@ export default function HomePage() {
   const [selectedChannel, setSelectedChannel] = useState<string | null>(null)
   const [activeTasks, setActiveTasks] = useState<any[]>([])
   const [refreshing, setRefreshing] = useState(false)
-  const [pollMs, setPollMs] = useState(1500)
-  const idleRoundsRef = useRef(0)
   const [retryingTasks, setRetryingTasks] = useState<Record<string, boolean>>({})
 
   const isActiveStatus = useCallback(
     (status: string | undefined) =>
       status === 'pending' ||
+      status === 'queued' ||
       status === 'downloading' ||
       status === 'transcribing' ||
       status === 'translating' ||
@@ -48,9 +48,9 @@ export default function HomePage() {
     try {
       const tasks = await GetAllTasks()
       console.log('All tasks loaded:', tasks)
-      // Filter tasks that already reached a terminal state
+      // Filter tasks that already reached a terminal state or wait to be resumed
       const completed = (tasks || []).filter(
-        (task) => task.status === 'done' || task.status === 'failed'
+        (task) => task.status === 'done' || task.status === 'failed' || task.status === 'paused'
       )
       // Sort by createdAt in descending order (newest first)
       completed.sort((a, b) => {
@@ -67,29 +67,15 @@ export default function HomePage() {
     }
   }, [])
 
-  const adjustPollingCadence = useCallback((activeCount: number) => {
-    if (activeCount > 0) {
-      idleRoundsRef.current = 0
-      setPollMs(1500)
-      return
-    }
-
-    idleRoundsRef.current = Math.min(idleRoundsRef.current + 1, 3)
-    const schedule = [10000, 30000, 60000]
-    const idx = Math.min(idleRoundsRef.current - 1, schedule.length - 1)
-    setPollMs(schedule[idx])
-  }, [])
-
   const loadActiveTasks = useCallback(async () => {
     try {
       const tasks = await ListActiveTasks()
       const running = (tasks || []).filter((task) => isActiveStatus(task.status))
       setActiveTasks(running)
-      adjustPollingCadence(running.length)
     } catch (err) {
       console.error('Failed to refresh active tasks:', err)
     }
-  }, [adjustPollingCadence, isActiveStatus])
+  }, [isActiveStatus])
 
   useEffect(() => {
     loadTasks()
@@ -102,11 +88,31 @@ export default function HomePage() {
       loadActiveTasks()
     })
 
+    // Apply task updates pushed by the backend as they happen
+    const offUpdated = EventsOn('task:updated', (task: types.Task) => {
+      setActiveTasks((current) => {
+        if (!isActiveStatus(task.status)) {
+          return current.filter((existing) => existing.id !== task.id)
+        }
+        const index = current.findIndex((existing) => existing.id === task.id)
+        if (index < 0) {
+          return [...current, task]
+        }
+        const next = [...current]
+        next[index] = task
+        return next
+      })
+      if (!isActiveStatus(task.status)) {
+        loadTasks()
+      }
+    })
+
     // Cleanup on unmount
     return () => {
       offReload()
+      offUpdated()
     }
-  }, [loadTasks, loadActiveTasks])
+  }, [loadTasks, loadActiveTasks, isActiveStatus])
 
   useEffect(() => {
     const params = new URLSearchParams(location.search)
@@ -114,19 +120,9 @@ export default function HomePage() {
     setSelectedChannel(channel)
   }, [location])
 
-  useEffect(() => {
-    const id = setInterval(() => {
-      loadActiveTasks()
-    }, pollMs)
-
-    return () => clearInterval(id)
-  }, [pollMs, loadActiveTasks])
-
   const handleManualRefresh = useCallback(async () => {
     setRefreshing(true)
     setLoading(true)
-    idleRoundsRef.current = 0
-    setPollMs(1500)
     try {
       await Promise.all([loadTasks(), loadActiveTasks()])
     } catch (err) {
@@ -176,6 +172,27 @@ export default function HomePage() {
     }
   }, [loadActiveTasks, loadTasks])
 
+  const handlePauseResume = useCallback(async (taskId: string, paused: boolean) => {
+    setRetryingTasks((prev) => ({ ...prev, [taskId]: true }))
+    try {
+      if (paused) {
+        await ResumeTask(taskId)
+      } else {
+        await PauseTask(taskId)
+      }
+      await Promise.all([loadTasks(), loadActiveTasks()])
+    } catch (err) {
+      console.error(paused ? 'Resume failed:' : 'Pause failed:', err)
+      const message = err instanceof Error ? err.message : `Failed to ${paused ? 'resume' : 'pause'} task`
+      alert(message)
+    } finally {
+      setRetryingTasks((prev) => {
+        const { [taskId]: _removed, ...rest } = prev
+        return rest
+      })
+    }
+  }, [loadActiveTasks, loadTasks])
+
   const combinedTasks = useMemo(() => {
     const tasks = [...activeTasks, ...processedVideos]
     return tasks.sort((a, b) => {
@@ -204,6 +221,8 @@ export default function HomePage() {
 
   const statusLabels: Record<string, string> = {
     pending: 'Pending',
+    queued: 'Queued',
+    paused: 'Paused',
     downloading: 'Downloading',
     transcribing: 'Transcribing',
     translating: 'Translating',
@@ -366,6 +385,20 @@ export default function HomePage() {
                     </p>
                   </div>
 
+                  {(isActiveStatus(task.status) || task.status === 'paused') && (
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      className="w-fit"
+                      onClick={() => handlePauseResume(task.id, task.status === 'paused')}
+                      disabled={!!retryingTasks[task.id]}
+                      type="button"
+                    >
+                      {retryingTasks[task.id] && <Loader2 className="h-4 w-4 mr-2 animate-spin" />}
+                      {task.status === 'paused' ? 'Resume' : 'Pause'}
+                    </Button>
+                  )}
+
                   {task.status === 'failed' && (
                     <div className="space-y-2">
                       {task.error && (
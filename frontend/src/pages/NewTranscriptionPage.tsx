@@ -7,8 +7,11 @@ import { AlertCircle, ChevronLeft, Download } from 'lucide-react'
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select'
 import VideoPreview, { VideoMetadata } from '@/components/VideoPreview'
 import TaskProgress, { TaskStage } from '@/components/TaskProgress'
-import { CheckDependencies, StartTranscription, GetTask, DetectPlatform, GetChannelLanguagePreference } from '../../wailsjs/go/main/App'
+import { CheckDependencies, StartTranscription, GetTask, DetectPlatform, GetChannelLanguagePreference, GetSettings } from '../../wailsjs/go/main/App'
 import { useDebounce } from '@/hooks'
+import { formatBytes } from '@/lib/utils'
+import { EventsOn } from '../../wailsjs/runtime/runtime'
+import { types } from '../../wailsjs/go/models'
 
 export default function NewTranscriptionPage() {
   const navigate = useNavigate()
@@ -29,17 +32,34 @@ export default function NewTranscriptionPage() {
   const [dependencies, setDependencies] = useState({
     ytdlp: false,
     ffmpeg: false,
+    ffprobe: false,
     yap: false
   })
   const [currentTaskId, setCurrentTaskId] = useState<string | null>(null)
+  const [formatPresets, setFormatPresets] = useState<types.FormatPreset[]>([])
+  const [formatPreset, setFormatPreset] = useState('')
+
+  useEffect(() => {
+    GetSettings()
+      .then((settings) => {
+        setFormatPresets(settings.formatPresets || [])
+        setFormatPreset(settings.defaultFormatPreset || '')
+      })
+      .catch((err) => console.error('Failed to load format presets:', err))
+  }, [])
 
   useEffect(() => {
     // Check dependencies on mount
     checkDependencies()
-    // Poll for task status if processing
+    // Follow task updates pushed by the backend while processing
     if (isProcessing && currentTaskId) {
-      const interval = setInterval(pollTaskStatus, 1000)
-      return () => clearInterval(interval)
+      loadTaskStatus()
+      const offUpdated = EventsOn('task:updated', (task: types.Task) => {
+        if (task.id === currentTaskId) {
+          applyTaskUpdate(task)
+        }
+      })
+      return () => offUpdated()
     }
   }, [isProcessing, currentTaskId])
 
@@ -82,13 +102,13 @@ export default function NewTranscriptionPage() {
       case 'bilibili':
         return {
           label: 'Bilibili URL',
-          placeholder: 'https://www.bilibili.com/video/BV...',
+          placeholder: 'https://www.bilibili.com/video/BV... or https://b23.tv/...',
           description: 'Download and process a Bilibili video with AI-powered transcription and translation'
         }
       default:
         return {
           label: 'Video URL',
-          placeholder: 'https://... (YouTube, Bilibili, etc.)',
+          placeholder: 'https://... (YouTube, Bilibili, any site yt-dlp supports, or a direct media link)',
           description: 'Download and process a video with AI-powered transcription and translation'
         }
     }
@@ -103,33 +123,49 @@ export default function NewTranscriptionPage() {
     }
   }
 
-  const pollTaskStatus = async () => {
+  const applyTaskUpdate = (task: types.Task) => {
+    // Map backend status to frontend TaskStage
+    const stageMap: Record<string, TaskStage> = {
+      'pending': 'pending',
+      'queued': 'pending',
+      'downloading': 'downloading',
+      'transcribing': 'transcribing',
+      'translating': 'translating',
+      'summarizing': 'summarizing',
+      'done': 'done',
+      'failed': 'failed'
+    }
+    setTaskStage(stageMap[task.status] || 'pending')
+    setTaskProgress(task.progress)
+    if (task.transfer) {
+      const { downloadedBytes, totalBytes, speed, eta } = task.transfer
+      const size = totalBytes > 0
+        ? `${formatBytes(downloadedBytes)} / ${formatBytes(totalBytes)}`
+        : formatBytes(downloadedBytes)
+      setTaskDetail(speed > 0 ? `${size} · ${formatBytes(speed)}/s` : size)
+      setEstimatedTime(eta > 0 ? `${Math.floor(eta / 60)}:${String(eta % 60).padStart(2, '0')}` : '')
+    } else {
+      setTaskDetail('')
+      setEstimatedTime('')
+    }
+
+    if (task.status === 'done') {
+      setIsProcessing(false)
+      setTimeout(() => navigate('/'), 2000)
+    } else if (task.status === 'failed') {
+      setIsProcessing(false)
+      setError(task.error || 'Task failed')
+    }
+  }
+
+  const loadTaskStatus = async () => {
     try {
       if (!currentTaskId) {
         return
       }
       const task = await GetTask(currentTaskId)
       if (task) {
-        // Map backend status to frontend TaskStage
-        const stageMap: Record<string, TaskStage> = {
-          'pending': 'pending',
-          'downloading': 'downloading',
-          'transcribing': 'transcribing',
-          'translating': 'translating',
-          'summarizing': 'summarizing',
-          'done': 'done',
-          'failed': 'failed'
-        }
-        setTaskStage(stageMap[task.status] || 'pending')
-        setTaskProgress(task.progress)
-        
-        if (task.status === 'done') {
-          setIsProcessing(false)
-          setTimeout(() => navigate('/'), 2000)
-        } else if (task.status === 'failed') {
-          setIsProcessing(false)
-          setError(task.error || 'Task failed')
-        }
+        applyTaskUpdate(task)
       } else {
         setIsProcessing(false)
       }
@@ -145,7 +181,7 @@ export default function NewTranscriptionPage() {
       setTaskStage('pending')
       setError('')
       
-      const task = await StartTranscription(url, sourceLang)
+      const task = await StartTranscription(url, sourceLang, formatPreset)
       setCurrentTaskId(task.id)
       setTaskStage('downloading')
     } catch (err: any) {
@@ -261,6 +297,22 @@ export default function NewTranscriptionPage() {
                 </SelectContent>
               </Select>
             </div>
+
+            {formatPresets.length > 0 && (
+              <div className="space-y-2">
+                <label className="text-sm font-medium">Download Format</label>
+                <Select value={formatPreset} onValueChange={setFormatPreset} disabled={isProcessing}>
+                  <SelectTrigger>
+                    <SelectValue placeholder="Select format" />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {formatPresets.map((preset) => (
+                      <SelectItem key={preset.name} value={preset.name}>{preset.name}</SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+            )}
             
             
             
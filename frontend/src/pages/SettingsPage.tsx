@@ -10,10 +10,16 @@ import {
   AlertCircle,
   CheckCircle2
 } from 'lucide-react'
-import { GetSettings, UpdateSettings } from '../../wailsjs/go/main/App'
+import { GetSettings, UpdateSettings, SetPlatformAuth } from '../../wailsjs/go/main/App'
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select'
 import { types } from '../../wailsjs/go/models'
 
+// Platforms yt-dlp can sign in to with cookies
+const authPlatforms = [
+  { id: 'youtube', label: 'YouTube' },
+  { id: 'bilibili', label: 'Bilibili' }
+]
+
 export default function SettingsPage() {
   const [settings, setSettings] = useState<types.Settings | null>({
     workspace: '~/Downloads/TransCube',
@@ -24,12 +30,17 @@ export default function SettingsPage() {
     summaryLanguage: 'en',
     temperature: 0.3,
     maxTokens: 4096,
-    channelLanguagePrefs: {}
+    channelLanguagePrefs: {},
+    downloadMode: 'video',
+    formatPresets: [],
+    defaultFormatPreset: '',
+    network: {}
   })
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
   const [saved, setSaved] = useState(false)
   const [error, setError] = useState('')
+  const [authDrafts, setAuthDrafts] = useState<Record<string, types.PlatformAuth>>({})
 
   useEffect(() => {
     loadSettings()
@@ -53,16 +64,31 @@ export default function SettingsPage() {
     setError('')
     
     try {
-      await UpdateSettings(settings)
+      const updated = await UpdateSettings(settings)
+      setSettings(updated)
       setSaved(true)
       setTimeout(() => setSaved(false), 3000)
-    } catch (err) {
-      setError('Failed to save settings')
+    } catch (err: any) {
+      setError(`Failed to save settings: ${err?.message || String(err)}`)
     } finally {
       setSaving(false)
     }
   }
 
+  const authDraft = (platform: string): types.PlatformAuth =>
+    authDrafts[platform] ?? settings?.auth?.[platform] ?? {}
+
+  const handleAuthSave = async (platform: string, auth: types.PlatformAuth) => {
+    try {
+      const updated = await SetPlatformAuth(platform, types.PlatformAuth.createFrom(auth))
+      setSettings(updated)
+      setAuthDrafts(({ [platform]: _, ...rest }) => rest)
+      setError('')
+    } catch (err: any) {
+      setError(err?.message || String(err))
+    }
+  }
+
   const handleWorkspaceSelect = () => {
     const input = document.createElement('input')
     input.type = 'file'
@@ -139,6 +165,45 @@ export default function SettingsPage() {
               All video files, subtitles, and summaries will be stored in this directory
             </p>
           </div>
+
+          <div className="space-y-2">
+            <label className="text-sm font-medium">Download Mode</label>
+            <Select
+              value={settings.downloadMode || 'video'}
+              onValueChange={(v) => setSettings({ ...settings, downloadMode: v })}
+            >
+              <SelectTrigger>
+                <SelectValue placeholder="Select download mode" />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="video">Video and audio</SelectItem>
+                <SelectItem value="audio">Audio only</SelectItem>
+              </SelectContent>
+            </Select>
+            <p className="text-xs text-muted-foreground">
+              Audio only skips the video stream; use it when you only need the transcript
+            </p>
+          </div>
+
+          <div className="space-y-2">
+            <label className="text-sm font-medium">Default Format</label>
+            <Select
+              value={settings.defaultFormatPreset}
+              onValueChange={(v) => setSettings({ ...settings, defaultFormatPreset: v })}
+            >
+              <SelectTrigger>
+                <SelectValue placeholder="Select format" />
+              </SelectTrigger>
+              <SelectContent>
+                {(settings.formatPresets || []).map((preset) => (
+                  <SelectItem key={preset.name} value={preset.name}>{preset.name}</SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            <p className="text-xs text-muted-foreground">
+              Format new tasks are downloaded in. Custom presets can be added to formatPresets in settings.json
+            </p>
+          </div>
           
         </CardContent>
       </Card>
@@ -250,7 +315,132 @@ export default function SettingsPage() {
         </CardContent>
       </Card>
 
-      
+      <Card>
+        <CardHeader>
+          <CardTitle>Network</CardTitle>
+          <CardDescription>
+            Proxy and throttling for downloads; the proxy is also used for AI requests
+          </CardDescription>
+        </CardHeader>
+        <CardContent className="space-y-4">
+          <div className="space-y-2">
+            <label className="text-sm font-medium">Proxy</label>
+            <Input
+              value={settings.network?.proxy || ''}
+              onChange={(e) => setSettings({ ...settings, network: { ...settings.network, proxy: e.target.value } })}
+              placeholder="http://proxy.example.com:8080 or socks5://127.0.0.1:1080"
+            />
+            <p className="text-xs text-muted-foreground">
+              Leave empty to connect directly or use the system proxy environment
+            </p>
+          </div>
+
+          <div className="grid grid-cols-2 gap-4">
+            <div className="space-y-2">
+              <label className="text-sm font-medium">Rate Limit</label>
+              <Input
+                value={settings.network?.rateLimit || ''}
+                onChange={(e) => setSettings({ ...settings, network: { ...settings.network, rateLimit: e.target.value } })}
+                placeholder="e.g. 2M"
+              />
+              <p className="text-xs text-muted-foreground">
+                Maximum download speed in bytes per second
+              </p>
+            </div>
+
+            <div className="space-y-2">
+              <label className="text-sm font-medium">Retries</label>
+              <Input
+                type="number"
+                min="0"
+                max="50"
+                value={settings.network?.retries || 0}
+                onChange={(e) => setSettings({ ...settings, network: { ...settings.network, retries: parseInt(e.target.value) || 0 } })}
+              />
+              <p className="text-xs text-muted-foreground">
+                Retries per request and fragment (0 uses the yt-dlp default)
+              </p>
+            </div>
+
+            <div className="space-y-2">
+              <label className="text-sm font-medium">Sleep Between Requests</label>
+              <Input
+                type="number"
+                min="0"
+                step="0.5"
+                value={settings.network?.sleepRequests || 0}
+                onChange={(e) => setSettings({ ...settings, network: { ...settings.network, sleepRequests: parseFloat(e.target.value) || 0 } })}
+              />
+              <p className="text-xs text-muted-foreground">
+                Seconds to wait between metadata requests
+              </p>
+            </div>
+
+            <div className="space-y-2">
+              <label className="text-sm font-medium">Sleep Before Downloads</label>
+              <Input
+                type="number"
+                min="0"
+                step="1"
+                value={settings.network?.sleepInterval || 0}
+                onChange={(e) => setSettings({ ...settings, network: { ...settings.network, sleepInterval: parseFloat(e.target.value) || 0 } })}
+              />
+              <p className="text-xs text-muted-foreground">
+                Seconds to wait before each download starts
+              </p>
+            </div>
+          </div>
+        </CardContent>
+      </Card>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>Authentication</CardTitle>
+          <CardDescription>
+            Cookies for age-restricted, members-only and high-resolution videos
+          </CardDescription>
+        </CardHeader>
+        <CardContent className="space-y-6">
+          {authPlatforms.map(({ id, label }) => {
+            const draft = authDraft(id)
+            const signedIn = !!(settings.auth?.[id]?.cookiesFile || settings.auth?.[id]?.cookiesFromBrowser)
+            return (
+              <div key={id} className="space-y-2">
+                <div className="flex items-center justify-between">
+                  <label className="text-sm font-medium">{label}</label>
+                  {signedIn && <Badge variant="success">Cookies set</Badge>}
+                </div>
+                <div className="grid grid-cols-2 gap-4">
+                  <Input
+                    value={draft.cookiesFile || ''}
+                    onChange={(e) => setAuthDrafts({ ...authDrafts, [id]: { ...draft, cookiesFile: e.target.value } })}
+                    placeholder="Path to cookies.txt"
+                  />
+                  <Input
+                    value={draft.cookiesFromBrowser || ''}
+                    onChange={(e) => setAuthDrafts({ ...authDrafts, [id]: { ...draft, cookiesFromBrowser: e.target.value } })}
+                    placeholder="Browser, e.g. firefox or chrome:Profile 1"
+                  />
+                </div>
+                <div className="flex space-x-2">
+                  <Button variant="outline" size="sm" onClick={() => handleAuthSave(id, draft)} disabled={!authDrafts[id]}>
+                    <Key className="mr-2 h-4 w-4" />
+                    Apply
+                  </Button>
+                  {signedIn && (
+                    <Button variant="outline" size="sm" onClick={() => handleAuthSave(id, {})}>
+                      Remove
+                    </Button>
+                  )}
+                </div>
+              </div>
+            )
+          })}
+          <p className="text-xs text-muted-foreground">
+            Use either a Netscape-format cookies file, which is copied into the app's private config folder, or a browser to read cookies from. Export them again when downloads report that sign-in is required.
+          </p>
+        </CardContent>
+      </Card>
     </div>
   )
 }
@@ -25,7 +25,9 @@ import {
   UpdateTaskSourceLanguage,
   DownloadTask,
   TranscribeTask,
-  SummarizeTask
+  SummarizeTask,
+  RefreshTaskMetadata,
+  GetTaskChapters
 } from '../../wailsjs/go/main/App'
 import { types, main } from '../../wailsjs/go/models'
 
@@ -39,6 +41,21 @@ type StructuredSummary = {
   }
 }
 
+const transcriptSourceLabels: Record<string, string> = {
+  official: 'Uploader subtitles',
+  auto: 'Automatic captions',
+  asr: 'Local speech recognition'
+}
+
+// formatChapterTime formats a chapter start in seconds as m:ss or h:mm:ss
+const formatChapterTime = (seconds: number) => {
+  const total = Math.floor(seconds)
+  const h = Math.floor(total / 3600)
+  const m = Math.floor((total % 3600) / 60)
+  const s = (total % 60).toString().padStart(2, '0')
+  return h > 0 ? `${h}:${m.toString().padStart(2, '0')}:${s}` : `${m}:${s}`
+}
+
 export default function TaskPage() {
   const { taskId } = useParams<{ taskId: string }>()
   const navigate = useNavigate()
@@ -48,12 +65,14 @@ export default function TaskPage() {
   const [videoSrc, setVideoSrc] = useState<string | null>(null)
   const [subtitles, setSubtitles] = useState<any[]>([])
   const [transcriptSubtitles, setTranscriptSubtitles] = useState<main.SubtitleEntry[]>([])
+  const [chapters, setChapters] = useState<types.Chapter[]>([])
   const [summary, setSummary] = useState<StructuredSummary | null>(null)
   const [selectedLang, setSelectedLang] = useState<string>('en')
   const [isUpdatingLanguage, setIsUpdatingLanguage] = useState(false)
   const [isDownloading, setIsDownloading] = useState(false)
   const [isTranscribing, setIsTranscribing] = useState(false)
   const [isSummarizing, setIsSummarizing] = useState(false)
+  const [isRefreshingMetadata, setIsRefreshingMetadata] = useState(false)
   const [actionHistory, setActionHistory] = useState<
     { id: number; type: 'success' | 'error'; message: string; timestamp: number }[]
   >([])
@@ -105,14 +124,27 @@ export default function TaskPage() {
             // Detect available video container (mp4 preferred, fallback to webm)
             const mp4 = `/media/${task.id}/video.mp4`
             const webm = `/media/${task.id}/video.webm`
+            const audio = `/media/${task.id}/source.m4a`
             let chosen: string | null = null
 
-            try {
-              const headMp4 = await fetch(mp4, { method: 'HEAD' })
-              if (headMp4.ok) {
-                chosen = mp4
-              }
-            } catch {}
+            // Imported files are played from the file itself
+            if (task.sourceFile) {
+              chosen = `/media/${task.id}/${encodeURIComponent(task.sourceFile)}`
+            }
+
+            // The file the last download produced
+            if (!chosen && task.format?.file) {
+              chosen = `/media/${task.id}/${encodeURIComponent(task.format.file)}`
+            }
+
+            if (!chosen) {
+              try {
+                const headMp4 = await fetch(mp4, { method: 'HEAD' })
+                if (headMp4.ok) {
+                  chosen = mp4
+                }
+              } catch {}
+            }
 
             if (!chosen) {
               try {
@@ -123,6 +155,16 @@ export default function TaskPage() {
               } catch {}
             }
 
+            // Audio-only downloads
+            if (!chosen) {
+              try {
+                const headAudio = await fetch(audio, { method: 'HEAD' })
+                if (headAudio.ok) {
+                  chosen = audio
+                }
+              } catch {}
+            }
+
             if (chosen) {
               setVideoSrc(chosen)
             } else {
@@ -174,6 +216,12 @@ export default function TaskPage() {
             console.error('Failed to load transcript:', err)
           }
 
+          try {
+            setChapters(await GetTaskChapters(task.id))
+          } catch (err) {
+            console.error('Failed to load chapters:', err)
+          }
+
           // Load summary JSON from media server if available
           try {
             const res = await fetch(`/media/${task.id}/summary_structured.json`)
@@ -252,6 +300,26 @@ export default function TaskPage() {
     }
   }
 
+  const handleRefreshMetadata = async () => {
+    if (!taskId) return
+
+    setIsRefreshingMetadata(true)
+
+    try {
+      await RefreshTaskMetadata(taskId)
+      await loadTask()
+      pushFeedback('success', 'Video details refreshed.')
+      setStickyError(null)
+    } catch (err) {
+      console.error('Failed to refresh metadata:', err)
+      const message = err instanceof Error ? err.message : 'Failed to refresh video details'
+      pushFeedback('error', message)
+      setStickyError(message)
+    } finally {
+      setIsRefreshingMetadata(false)
+    }
+  }
+
   const handleRetranscribe = async () => {
     if (!taskId) return
 
@@ -356,25 +424,42 @@ export default function TaskPage() {
             <ChevronLeft className="mr-2 h-4 w-4" />
             Back to Library
           </Button>
-          {video.videoId && (
-            <Button 
-              variant="outline" 
-              size="sm"
-              onClick={handleCopyLink}
-            >
-              {copied ? (
-                <>
-                  <Check className="mr-2 h-4 w-4" />
-                  Copied!
-                </>
-              ) : (
-                <>
-                  <Copy className="mr-2 h-4 w-4" />
-                  Copy Link
-                </>
-              )}
-            </Button>
-          )}
+          <div className="flex items-center gap-2">
+            {video.platform !== 'local' && (
+              <Button
+                variant="outline"
+                size="sm"
+                onClick={handleRefreshMetadata}
+                disabled={isRefreshingMetadata}
+              >
+                {isRefreshingMetadata ? (
+                  <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+                ) : (
+                  <RefreshCcw className="mr-2 h-4 w-4" />
+                )}
+                Refresh Details
+              </Button>
+            )}
+            {video.videoId && (
+              <Button 
+                variant="outline" 
+                size="sm"
+                onClick={handleCopyLink}
+              >
+                {copied ? (
+                  <>
+                    <Check className="mr-2 h-4 w-4" />
+                    Copied!
+                  </>
+                ) : (
+                  <>
+                    <Copy className="mr-2 h-4 w-4" />
+                    Copy Link
+                  </>
+                )}
+              </Button>
+            )}
+          </div>
         </div>
       </div>
 
@@ -467,6 +552,35 @@ export default function TaskPage() {
                       <p>
                         <span className="text-muted-foreground">Source Language:</span> {video.sourceLang || 'Not specified'}
                       </p>
+                      {video.transcriptSource && (
+                        <p>
+                          <span className="text-muted-foreground">Transcript:</span>{' '}
+                          {transcriptSourceLabels[video.transcriptSource] || video.transcriptSource}
+                        </p>
+                      )}
+                      {video.format && (
+                        <p>
+                          <span className="text-muted-foreground">Format:</span>{' '}
+                          {video.format.preset}
+                          {video.format.height ? ` (${video.format.width}×${video.format.height})` : ''}
+                        </p>
+                      )}
+                      {video.metadata?.publishedAt && (
+                        <p>
+                          <span className="text-muted-foreground">Published:</span> {new Date(video.metadata.publishedAt).toLocaleDateString()}
+                        </p>
+                      )}
+                      {(video.metadata?.viewCount ?? 0) > 0 && (
+                        <p>
+                          <span className="text-muted-foreground">Views:</span> {video.metadata!.viewCount.toLocaleString()}
+                          {video.metadata!.likeCount > 0 && ` · ${video.metadata!.likeCount.toLocaleString()} likes`}
+                        </p>
+                      )}
+                      {video.metadata?.tags && video.metadata.tags.length > 0 && (
+                        <p>
+                          <span className="text-muted-foreground">Tags:</span> {video.metadata.tags.join(', ')}
+                        </p>
+                      )}
                       <p>
                         <span className="text-muted-foreground">Status:</span> {video.status}
                       </p>
@@ -487,6 +601,32 @@ export default function TaskPage() {
                       )}
                     </div>
                   </div>
+                  {chapters.length > 0 && (
+                    <div>
+                      <h3 className="mb-2 text-lg font-semibold">Chapters</h3>
+                      <div className="space-y-1 text-sm">
+                        {chapters.map((chapter) => (
+                          <button
+                            key={chapter.start}
+                            type="button"
+                            className="flex w-full items-start gap-4 rounded p-1 -mx-1 text-left hover:bg-accent/50"
+                            onClick={() => videoPlayerRef.current?.seekTo(chapter.start)}
+                          >
+                            <span className="min-w-[64px] font-mono text-muted-foreground">
+                              {formatChapterTime(chapter.start)}
+                            </span>
+                            <span>{chapter.title}</span>
+                          </button>
+                        ))}
+                      </div>
+                    </div>
+                  )}
+                  {video.metadata?.description && (
+                    <div>
+                      <h3 className="mb-2 text-lg font-semibold">Description</h3>
+                      <p className="whitespace-pre-wrap text-sm text-muted-foreground">{video.metadata.description}</p>
+                    </div>
+                  )}
                   <div>
                     <h3 className="mb-2 text-lg font-semibold">Manual Controls</h3>
                     <div className="space-y-4">
@@ -5,13 +5,22 @@ export interface TaskStatus {
   channel: string
   videoId: string
   sourceLang: string
-  status: 'pending' | 'downloading' | 'transcribing' | 'translating' | 'summarizing' | 'done' | 'failed'
+  status: 'pending' | 'queued' | 'downloading' | 'transcribing' | 'translating' | 'summarizing' | 'paused' | 'done' | 'failed' | 'cancelled'
   progress: number
   error?: string
+  errorCode?: string
+  transfer?: TransferStats
   createdAt: string
   updatedAt: string
 }
 
+export interface TransferStats {
+  downloadedBytes: number
+  totalBytes: number
+  speed: number
+  eta: number
+}
+
 export interface Subtitle {
   index: number
   start: string
@@ -41,6 +50,7 @@ export interface QASummary {
 export interface DependencyStatus {
   ytdlp: boolean
   ffmpeg: boolean
+  ffprobe: boolean
   yap: boolean
 }
 
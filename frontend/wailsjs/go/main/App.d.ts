@@ -3,35 +3,75 @@
 import {types} from '../models';
 import {main} from '../models';
 
+export function AddSubscription(arg1:string):Promise<types.Subscription>;
+
+export function CancelTask(arg1:string):Promise<types.Task>;
+
 export function CheckDependencies():Promise<types.DependencyStatus>;
 
+export function CheckSubscriptions():Promise<number>;
+
 export function DeleteTask(arg1:string):Promise<void>;
 
 export function DetectPlatform(arg1:string):Promise<string>;
 
 export function DownloadTask(arg1:string):Promise<types.Task>;
 
+export function ExpandCollection(arg1:string):Promise<types.Collection>;
+
 export function GetAllTasks():Promise<Array<types.Task>>;
 
 export function GetChannelLanguagePreference(arg1:string,arg2:string,arg3:string):Promise<string>;
 
 export function GetDebugInfo():Promise<Record<string, string>>;
 
+export function GetQueue():Promise<Array<types.Task>>;
+
 export function GetSettings():Promise<types.Settings>;
 
 export function GetTask(arg1:string):Promise<types.Task>;
 
+export function GetTaskChapters(arg1:string):Promise<Array<types.Chapter>>;
+
 export function GetTaskSubtitles(arg1:string):Promise<Array<main.SubtitleEntry>>;
 
+export function GetTaskTimeline(arg1:string):Promise<Array<types.StageRecord>>;
+
+export function ImportLocalFile(arg1:string,arg2:string):Promise<types.Task>;
+
 export function ListActiveTasks():Promise<Array<types.Task>>;
 
+export function ListSubscriptions():Promise<Array<types.Subscription>>;
+
+export function MoveQueuedTask(arg1:string,arg2:number):Promise<Array<types.Task>>;
+
 export function ParseVideoUrl(arg1:string):Promise<types.VideoMetadata>;
 
+export function PauseTask(arg1:string):Promise<types.Task>;
+
+export function RefreshTaskMetadata(arg1:string):Promise<types.Task>;
+
+export function RemoveSubscription(arg1:string):Promise<void>;
+
+export function RerunFrom(arg1:string,arg2:string,arg3:types.RerunOptions):Promise<types.Task>;
+
+export function ResumeTask(arg1:string):Promise<types.Task>;
+
 export function RetryTask(arg1:string):Promise<types.Task>;
 
 export function SetChannelLanguagePreference(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
-export function StartTranscription(arg1:string,arg2:string):Promise<types.Task>;
+export function SetPlatformAuth(arg1:string,arg2:types.PlatformAuth):Promise<types.Settings>;
+
+export function SetSubscriptionEnabled(arg1:string,arg2:boolean):Promise<types.Subscription>;
+
+export function SetTaskDownloadMode(arg1:string,arg2:string):Promise<types.Task>;
+
+export function SetTaskForceASR(arg1:string,arg2:boolean):Promise<types.Task>;
+
+export function StartCollection(arg1:types.Collection,arg2:string):Promise<Array<types.Task>>;
+
+export function StartTranscription(arg1:string,arg2:string,arg3:string):Promise<types.Task>;
 
 export function SummarizeTask(arg1:string):Promise<types.Task>;
 
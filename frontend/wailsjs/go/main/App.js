@@ -2,10 +2,22 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddSubscription(arg1) {
+  return window['go']['main']['App']['AddSubscription'](arg1);
+}
+
+export function CancelTask(arg1) {
+  return window['go']['main']['App']['CancelTask'](arg1);
+}
+
 export function CheckDependencies() {
   return window['go']['main']['App']['CheckDependencies']();
 }
 
+export function CheckSubscriptions() {
+  return window['go']['main']['App']['CheckSubscriptions']();
+}
+
 export function DeleteTask(arg1) {
   return window['go']['main']['App']['DeleteTask'](arg1);
 }
@@ -18,6 +30,10 @@ export function DownloadTask(arg1) {
   return window['go']['main']['App']['DownloadTask'](arg1);
 }
 
+export function ExpandCollection(arg1) {
+  return window['go']['main']['App']['ExpandCollection'](arg1);
+}
+
 export function GetAllTasks() {
   return window['go']['main']['App']['GetAllTasks']();
 }
@@ -30,6 +46,10 @@ export function GetDebugInfo() {
   return window['go']['main']['App']['GetDebugInfo']();
 }
 
+export function GetQueue() {
+  return window['go']['main']['App']['GetQueue']();
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
@@ -38,18 +58,58 @@ export function GetTask(arg1) {
   return window['go']['main']['App']['GetTask'](arg1);
 }
 
+export function GetTaskChapters(arg1) {
+  return window['go']['main']['App']['GetTaskChapters'](arg1);
+}
+
 export function GetTaskSubtitles(arg1) {
   return window['go']['main']['App']['GetTaskSubtitles'](arg1);
 }
 
+export function GetTaskTimeline(arg1) {
+  return window['go']['main']['App']['GetTaskTimeline'](arg1);
+}
+
+export function ImportLocalFile(arg1, arg2) {
+  return window['go']['main']['App']['ImportLocalFile'](arg1, arg2);
+}
+
 export function ListActiveTasks() {
   return window['go']['main']['App']['ListActiveTasks']();
 }
 
+export function ListSubscriptions() {
+  return window['go']['main']['App']['ListSubscriptions']();
+}
+
+export function MoveQueuedTask(arg1, arg2) {
+  return window['go']['main']['App']['MoveQueuedTask'](arg1, arg2);
+}
+
 export function ParseVideoUrl(arg1) {
   return window['go']['main']['App']['ParseVideoUrl'](arg1);
 }
 
+export function PauseTask(arg1) {
+  return window['go']['main']['App']['PauseTask'](arg1);
+}
+
+export function RefreshTaskMetadata(arg1) {
+  return window['go']['main']['App']['RefreshTaskMetadata'](arg1);
+}
+
+export function RemoveSubscription(arg1) {
+  return window['go']['main']['App']['RemoveSubscription'](arg1);
+}
+
+export function RerunFrom(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RerunFrom'](arg1, arg2, arg3);
+}
+
+export function ResumeTask(arg1) {
+  return window['go']['main']['App']['ResumeTask'](arg1);
+}
+
 export function RetryTask(arg1) {
   return window['go']['main']['App']['RetryTask'](arg1);
 }
@@ -58,8 +118,28 @@ export function SetChannelLanguagePreference(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['SetChannelLanguagePreference'](arg1, arg2, arg3, arg4);
 }
 
-export function StartTranscription(arg1, arg2) {
-  return window['go']['main']['App']['StartTranscription'](arg1, arg2);
+export function SetPlatformAuth(arg1, arg2) {
+  return window['go']['main']['App']['SetPlatformAuth'](arg1, arg2);
+}
+
+export function SetSubscriptionEnabled(arg1, arg2) {
+  return window['go']['main']['App']['SetSubscriptionEnabled'](arg1, arg2);
+}
+
+export function SetTaskDownloadMode(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskDownloadMode'](arg1, arg2);
+}
+
+export function SetTaskForceASR(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskForceASR'](arg1, arg2);
+}
+
+export function StartCollection(arg1, arg2) {
+  return window['go']['main']['App']['StartCollection'](arg1, arg2);
+}
+
+export function StartTranscription(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartTranscription'](arg1, arg2, arg3);
 }
 
 export function SummarizeTask(arg1) {
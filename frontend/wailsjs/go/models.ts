@@ -7,6 +7,7 @@ export namespace main {
 	    endTime: string;
 	    english: string;
 	    chinese?: string;
+	    chapter?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new SubtitleEntry(source);
@@ -20,6 +21,7 @@ export namespace main {
 	        this.endTime = source["endTime"];
 	        this.english = source["english"];
 	        this.chinese = source["chinese"];
+	        this.chapter = source["chapter"];
 	    }
 	}
 
@@ -27,9 +29,102 @@ export namespace main {
 
 export namespace types {
 	
+	export class Captions {
+	    lang: string;
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Captions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lang = source["lang"];
+	        this.source = source["source"];
+	    }
+	}
+	export class Chapter {
+	    title: string;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Chapter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class CollectionEntry {
+	    id: string;
+	    url: string;
+	    title: string;
+	    channel: string;
+	    duration: number;
+	    thumbnail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CollectionEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.url = source["url"];
+	        this.title = source["title"];
+	        this.channel = source["channel"];
+	        this.duration = source["duration"];
+	        this.thumbnail = source["thumbnail"];
+	    }
+	}
+	export class Collection {
+	    id: string;
+	    platform: string;
+	    url: string;
+	    title: string;
+	    channel: string;
+	    entries: Array<CollectionEntry>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Collection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.platform = source["platform"];
+	        this.url = source["url"];
+	        this.title = source["title"];
+	        this.channel = source["channel"];
+	        this.entries = this.convertValues(source["entries"], CollectionEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class DependencyStatus {
 	    ytdlp: boolean;
 	    ffmpeg: boolean;
+	    ffprobe: boolean;
 	    yap: boolean;
 	
 	    static createFrom(source: any = {}) {
@@ -40,9 +135,104 @@ export namespace types {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.ytdlp = source["ytdlp"];
 	        this.ffmpeg = source["ffmpeg"];
+	        this.ffprobe = source["ffprobe"];
 	        this.yap = source["yap"];
 	    }
 	}
+	export class DownloadedFormat {
+	    preset: string;
+	    format: string;
+	    file: string;
+	    width?: number;
+	    height?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DownloadedFormat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preset = source["preset"];
+	        this.format = source["format"];
+	        this.file = source["file"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+	export class FormatPreset {
+	    name: string;
+	    format: string;
+	    fallbackFormat?: string;
+	    container?: string;
+	    maxFilesize?: string;
+	    audioOnly?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormatPreset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.format = source["format"];
+	        this.fallbackFormat = source["fallbackFormat"];
+	        this.container = source["container"];
+	        this.maxFilesize = source["maxFilesize"];
+	        this.audioOnly = source["audioOnly"];
+	    }
+	}
+	export class NetworkSettings {
+	    proxy?: string;
+	    rateLimit?: string;
+	    retries?: number;
+	    sleepRequests?: number;
+	    sleepInterval?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.proxy = source["proxy"];
+	        this.rateLimit = source["rateLimit"];
+	        this.retries = source["retries"];
+	        this.sleepRequests = source["sleepRequests"];
+	        this.sleepInterval = source["sleepInterval"];
+	    }
+	}
+	export class PlatformAuth {
+	    cookiesFile?: string;
+	    cookiesFromBrowser?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlatformAuth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.cookiesFile = source["cookiesFile"];
+	        this.cookiesFromBrowser = source["cookiesFromBrowser"];
+	    }
+	}
+	export class RerunOptions {
+	    sourceLang?: string;
+	    forceAsr?: boolean;
+	    downloadMode?: string;
+	    formatPreset?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RerunOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sourceLang = source["sourceLang"];
+	        this.forceAsr = source["forceAsr"];
+	        this.downloadMode = source["downloadMode"];
+	        this.formatPreset = source["formatPreset"];
+	    }
+	}
 	export class Settings {
 	    workspace: string;
 	    sourceLang: string;
@@ -53,6 +243,15 @@ export namespace types {
 	    temperature: number;
 	    maxTokens: number;
 	    channelLanguagePrefs: Record<string, string>;
+	    maxConcurrentDownloads: number;
+	    maxConcurrentTranscriptions: number;
+	    maxConcurrentSummaries: number;
+	    downloadMode: string;
+	    formatPresets: Array<FormatPreset>;
+	    defaultFormatPreset: string;
+	    network: NetworkSettings;
+	    auth?: Record<string, PlatformAuth>;
+	    subscriptionIntervalMinutes: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
@@ -69,7 +268,132 @@ export namespace types {
 	        this.temperature = source["temperature"];
 	        this.maxTokens = source["maxTokens"];
 	        this.channelLanguagePrefs = source["channelLanguagePrefs"];
+	        this.maxConcurrentDownloads = source["maxConcurrentDownloads"];
+	        this.maxConcurrentTranscriptions = source["maxConcurrentTranscriptions"];
+	        this.maxConcurrentSummaries = source["maxConcurrentSummaries"];
+	        this.downloadMode = source["downloadMode"];
+	        this.formatPresets = this.convertValues(source["formatPresets"], FormatPreset);
+	        this.defaultFormatPreset = source["defaultFormatPreset"];
+	        this.network = this.convertValues(source["network"], NetworkSettings);
+	        this.auth = this.convertValues(source["auth"], PlatformAuth, true);
+	        this.subscriptionIntervalMinutes = source["subscriptionIntervalMinutes"];
 	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StageRecord {
+	    stage: string;
+	    attempt: number;
+	    // Go type: time
+	    startedAt: any;
+	    // Go type: time
+	    endedAt?: any;
+	    durationMs: number;
+	    outcome: string;
+	    error?: string;
+	    errorCode?: string;
+	    toolVersions?: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new StageRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stage = source["stage"];
+	        this.attempt = source["attempt"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.endedAt = this.convertValues(source["endedAt"], null);
+	        this.durationMs = source["durationMs"];
+	        this.outcome = source["outcome"];
+	        this.error = source["error"];
+	        this.errorCode = source["errorCode"];
+	        this.toolVersions = source["toolVersions"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Subscription {
+	    id: string;
+	    url: string;
+	    platform: string;
+	    channel: string;
+	    channelId: string;
+	    enabled: boolean;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    lastCheckedAt?: any;
+	    lastError?: string;
+	    seenVideoIds?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Subscription(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.url = source["url"];
+	        this.platform = source["platform"];
+	        this.channel = source["channel"];
+	        this.channelId = source["channelId"];
+	        this.enabled = source["enabled"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.lastCheckedAt = this.convertValues(source["lastCheckedAt"], null);
+	        this.lastError = source["lastError"];
+	        this.seenVideoIds = source["seenVideoIds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class Task {
 	    id: string;
@@ -84,6 +408,7 @@ export namespace types {
 	    status: string;
 	    progress: number;
 	    error?: string;
+	    errorCode?: string;
 	    workDir: string;
 	    // Go type: time
 	    createdAt: any;
@@ -91,6 +416,20 @@ export namespace types {
 	    updatedAt: any;
 	    // Go type: time
 	    completedAt?: any;
+	    durationSeconds?: number;
+	    metadata?: VideoMetadata;
+	    recoveryAttempts?: number;
+	    transfer?: TransferStats;
+	    pausedStage?: string;
+	    captions?: Captions;
+	    transcriptSource?: string;
+	    downloadMode?: string;
+	    formatPreset?: string;
+	    format?: DownloadedFormat;
+	    forceAsr?: boolean;
+	    sourceFile?: string;
+	    collectionId?: string;
+	    stages?: Array<StageRecord>;
 	
 	    static createFrom(source: any = {}) {
 	        return new Task(source);
@@ -110,10 +449,25 @@ export namespace types {
 	        this.status = source["status"];
 	        this.progress = source["progress"];
 	        this.error = source["error"];
+	        this.errorCode = source["errorCode"];
 	        this.workDir = source["workDir"];
 	        this.createdAt = this.convertValues(source["createdAt"], null);
 	        this.updatedAt = this.convertValues(source["updatedAt"], null);
 	        this.completedAt = this.convertValues(source["completedAt"], null);
+	        this.durationSeconds = source["durationSeconds"];
+	        this.metadata = this.convertValues(source["metadata"], VideoMetadata);
+	        this.recoveryAttempts = source["recoveryAttempts"];
+	        this.transfer = this.convertValues(source["transfer"], TransferStats);
+	        this.pausedStage = source["pausedStage"];
+	        this.captions = this.convertValues(source["captions"], Captions);
+	        this.transcriptSource = source["transcriptSource"];
+	        this.downloadMode = source["downloadMode"];
+	        this.formatPreset = source["formatPreset"];
+	        this.format = this.convertValues(source["format"], DownloadedFormat);
+	        this.forceAsr = source["forceAsr"];
+	        this.sourceFile = source["sourceFile"];
+	        this.collectionId = source["collectionId"];
+	        this.stages = this.convertValues(source["stages"], StageRecord);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -134,6 +488,24 @@ export namespace types {
 		    return a;
 		}
 	}
+	export class TransferStats {
+	    downloadedBytes: number;
+	    totalBytes: number;
+	    speed: number;
+	    eta: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TransferStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.downloadedBytes = source["downloadedBytes"];
+	        this.totalBytes = source["totalBytes"];
+	        this.speed = source["speed"];
+	        this.eta = source["eta"];
+	    }
+	}
 	export class VideoMetadata {
 	    id: string;
 	    platform: string;
@@ -146,6 +518,7 @@ export namespace types {
 	    viewCount: number;
 	    likeCount: number;
 	    description: string;
+	    tags?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new VideoMetadata(source);
@@ -164,6 +537,7 @@ export namespace types {
 	        this.viewCount = source["viewCount"];
 	        this.likeCount = source["likeCount"];
 	        this.description = source["description"];
+	        this.tags = source["tags"];
 	    }
 	}
 
@@ -1,38 +1,210 @@
 package platform
 
 import (
-	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type BilibiliPlatform struct{}
 
+// Constants of the conversion between av numbers and BV IDs
+const (
+	bvAlphabet = "FcwAPNKTMug3GV5Lj7EJnHpWsx4tb8haYeviqBz6rkCy12mUSDQX9RdoZf"
+	bvPrefix   = "BV1"
+	bvXorCode  = 23442827791579
+	bvMaskCode = 1<<51 - 1
+	bvMaxAID   = 1 << 51
+)
+
+// bvEncodeMap gives the position in the BV ID of each base-58 digit of the
+// scrambled av number, least significant first
+var bvEncodeMap = [9]int{8, 7, 0, 5, 1, 3, 2, 4, 6}
+
+var (
+	bvIDPattern      = regexp.MustCompile(`^(?i:bv)1[` + bvAlphabet + `]{9}$`)
+	avIDPattern      = regexp.MustCompile(`^(?i:av)(\d{1,16})$`)
+	episodeIDPattern = regexp.MustCompile(`^ep(\d+)$`)
+)
+
+// bilibiliShortHosts redirect to a video page
+var bilibiliShortHosts = []string{"b23.tv", "bili2233.cn"}
+
+// BilibiliVideo is a link to a Bilibili video reduced to what identifies it
+type BilibiliVideo struct {
+	// BVID is the video's BV ID; legacy av numbers are converted to it
+	BVID string
+	// Page is the part of a multi-part upload, counted from 1
+	Page int
+	// Episode is the number of a bangumi episode, which is identified by it
+	// instead of a BV ID
+	Episode string
+}
+
+// ID returns the video ID tasks identify the video by. Parts after the first
+// get a _pN suffix, as yt-dlp names them, so each part is its own task.
+func (v BilibiliVideo) ID() string {
+	if v.Episode != "" {
+		return "ep" + v.Episode
+	}
+	if v.Page > 1 {
+		return v.BVID + "_p" + strconv.Itoa(v.Page)
+	}
+	return v.BVID
+}
+
+// CanonicalURL returns the page of the video, or episode, on www.bilibili.com
+func (v BilibiliVideo) CanonicalURL() string {
+	if v.Episode != "" {
+		return "https://www.bilibili.com/bangumi/play/ep" + v.Episode
+	}
+	if v.Page > 1 {
+		return "https://www.bilibili.com/video/" + v.BVID + "?p=" + strconv.Itoa(v.Page)
+	}
+	return "https://www.bilibili.com/video/" + v.BVID
+}
+
 func (b *BilibiliPlatform) Name() string {
 	return string(Bilibili)
 }
 
+// DetectURL reports whether rawURL is on a Bilibili host, including short
+// links, or is a bare BV ID or av number
 func (b *BilibiliPlatform) DetectURL(rawURL string) bool {
-	u, err := url.Parse(rawURL)
-	if err == nil && u.Host != "" {
-		host := strings.ToLower(u.Host)
-		if strings.Contains(host, "bilibili.com") {
+	rawURL = strings.TrimSpace(rawURL)
+	if bvIDPattern.MatchString(rawURL) || avIDPattern.MatchString(rawURL) {
+		return true
+	}
+	u, ok := parseLink(rawURL)
+	if !ok {
+		return false
+	}
+	return hostIn(u.Hostname(), "bilibili.com") || IsBilibiliShortLink(rawURL)
+}
+
+func (b *BilibiliPlatform) ExtractVideoID(rawURL string) string {
+	video, ok := ParseBilibiliURL(rawURL)
+	if !ok {
+		return ""
+	}
+	return video.ID()
+}
+
+// CanonicalURL returns the page of the video rawURL links to, or rawURL
+// itself when it does not link to a single video or is a short link
+func (b *BilibiliPlatform) CanonicalURL(rawURL string) string {
+	video, ok := ParseBilibiliURL(rawURL)
+	if !ok {
+		return rawURL
+	}
+	return video.CanonicalURL()
+}
+
+// IsBilibiliShortLink reports whether rawURL is a b23.tv style short link,
+// which has to be resolved before the video it points at is known
+func IsBilibiliShortLink(rawURL string) bool {
+	u, ok := parseLink(strings.TrimSpace(rawURL))
+	if !ok {
+		return false
+	}
+	for _, host := range bilibiliShortHosts {
+		if hostIn(u.Hostname(), host) {
 			return true
 		}
 	}
+	return false
+}
 
-	if strings.Contains(rawURL, "bilibili.com") {
-		return true
+// ParseBilibiliURL extracts the video a Bilibili link points at. It accepts
+// /video/ pages with BV IDs or av numbers and their ?p= part, the embedded
+// player, pages passing the video in a bvid parameter, bangumi episodes, and
+// bare BV IDs and av numbers. Short links are not resolved.
+func ParseBilibiliURL(rawURL string) (BilibiliVideo, bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	if bvid, ok := parseBilibiliID(rawURL); ok {
+		return BilibiliVideo{BVID: bvid, Page: 1}, true
 	}
 
-	return false
+	u, ok := parseLink(rawURL)
+	if !ok || !hostIn(u.Hostname(), "bilibili.com") {
+		return BilibiliVideo{}, false
+	}
+	query := u.Query()
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if len(segments) >= 3 && segments[0] == "bangumi" && segments[1] == "play" {
+		// ss links name a whole season, not one episode
+		if match := episodeIDPattern.FindStringSubmatch(segments[2]); match != nil {
+			return BilibiliVideo{Episode: match[1]}, true
+		}
+		return BilibiliVideo{}, false
+	}
+
+	var id string
+	switch {
+	case len(segments) >= 2 && segments[0] == "video":
+		id = segments[1]
+	case query.Get("bvid") != "":
+		id = query.Get("bvid")
+	case query.Get("aid") != "":
+		id = "av" + query.Get("aid")
+	}
+	bvid, ok := parseBilibiliID(id)
+	if !ok {
+		return BilibiliVideo{}, false
+	}
+
+	video := BilibiliVideo{BVID: bvid, Page: 1}
+	for _, key := range []string{"p", "page"} {
+		if page, err := strconv.Atoi(query.Get(key)); err == nil && page > 0 {
+			video.Page = page
+			break
+		}
+	}
+	return video, true
 }
 
-func (b *BilibiliPlatform) ExtractVideoID(rawURL string) string {
-	re := regexp.MustCompile(`(BV[a-zA-Z0-9]{10})`)
-	if match := re.FindStringSubmatch(rawURL); len(match) > 1 {
-		return match[1]
+// parseBilibiliID returns the BV ID of a BV ID or av number
+func parseBilibiliID(id string) (string, bool) {
+	if bvIDPattern.MatchString(id) {
+		return bvPrefix + id[3:], true
 	}
+	if match := avIDPattern.FindStringSubmatch(id); match != nil {
+		aid, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || aid == 0 || aid >= bvMaxAID {
+			return "", false
+		}
+		return AVToBV(aid), true
+	}
+	return "", false
+}
+
+// AVToBV converts a legacy av number to the BV ID of the same video
+func AVToBV(aid uint64) string {
+	var code [9]byte
+	n := (bvMaxAID | aid) ^ bvXorCode
+	for _, position := range bvEncodeMap {
+		code[position] = bvAlphabet[n%uint64(len(bvAlphabet))]
+		n /= uint64(len(bvAlphabet))
+	}
+	return bvPrefix + string(code[:])
+}
+
+// BVToAV converts a BV ID to the legacy av number of the same video
+func BVToAV(bvid string) (uint64, bool) {
+	if !bvIDPattern.MatchString(bvid) {
+		return 0, false
+	}
+	code := bvid[len(bvPrefix):]
+	var n uint64
+	for i := len(bvEncodeMap) - 1; i >= 0; i-- {
+		n = n*uint64(len(bvAlphabet)) + uint64(strings.IndexByte(bvAlphabet, code[bvEncodeMap[i]]))
+	}
+	return (n & bvMaskCode) ^ bvXorCode, true
+}
 
-	return ""
+// hostIn reports whether host is domain or one of its subdomains
+func hostIn(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
 }
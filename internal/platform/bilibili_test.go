@@ -0,0 +1,103 @@
+package platform
+
+import "testing"
+
+func TestParseBilibiliURL(t *testing.T) {
+	const bv = "BV1xx411c7mD"
+	tests := []struct {
+		name          string
+		url           string
+		wantID        string
+		wantCanonical string
+	}{
+		{"video", "https://www.bilibili.com/video/" + bv, bv, "https://www.bilibili.com/video/" + bv},
+		{"trailing slash and tracking", "https://www.bilibili.com/video/" + bv + "/?spm_id_from=333.788&vd_source=abc", bv, "https://www.bilibili.com/video/" + bv},
+		{"without scheme", "bilibili.com/video/" + bv, bv, "https://www.bilibili.com/video/" + bv},
+		{"mobile", "https://m.bilibili.com/video/" + bv, bv, "https://www.bilibili.com/video/" + bv},
+		{"lowercase prefix", "https://www.bilibili.com/video/bv1xx411c7mD", bv, "https://www.bilibili.com/video/" + bv},
+		{"first part", "https://www.bilibili.com/video/" + bv + "?p=1", bv, "https://www.bilibili.com/video/" + bv},
+		{"later part", "https://www.bilibili.com/video/" + bv + "/?p=3&share_source=copy", bv + "_p3", "https://www.bilibili.com/video/" + bv + "?p=3"},
+		{"invalid part ignored", "https://www.bilibili.com/video/" + bv + "?p=x", bv, "https://www.bilibili.com/video/" + bv},
+		{"av number", "https://www.bilibili.com/video/av2", bv, "https://www.bilibili.com/video/" + bv},
+		{"uppercase av number with part", "https://www.bilibili.com/video/AV170001?p=2", "BV17x411w7KC_p2", "https://www.bilibili.com/video/BV17x411w7KC?p=2"},
+		{"embedded player by bvid", "https://player.bilibili.com/player.html?bvid=" + bv + "&page=2", bv + "_p2", "https://www.bilibili.com/video/" + bv + "?p=2"},
+		{"embedded player by aid", "//player.bilibili.com/player.html?aid=170001&cid=279786", "BV17x411w7KC", "https://www.bilibili.com/video/BV17x411w7KC"},
+		{"watch later list", "https://www.bilibili.com/list/watchlater?bvid=" + bv + "&oid=2", bv, "https://www.bilibili.com/video/" + bv},
+		{"bangumi episode", "https://www.bilibili.com/bangumi/play/ep267851?from_spmid=666.25", "ep267851", "https://www.bilibili.com/bangumi/play/ep267851"},
+		{"bare BV ID", bv, bv, "https://www.bilibili.com/video/" + bv},
+		{"bare av number", " av170001\n", "BV17x411w7KC", "https://www.bilibili.com/video/BV17x411w7KC"},
+
+		{"bangumi season", "https://www.bilibili.com/bangumi/play/ss33802", "", ""},
+		{"short link", "https://b23.tv/AbCdEf1", "", ""},
+		{"space", "https://space.bilibili.com/2/video", "", ""},
+		{"BV ID too short", "https://www.bilibili.com/video/BV1xx411c7m", "", ""},
+		{"BV ID with invalid characters", "https://www.bilibili.com/video/BV1xx411c7m0", "", ""},
+		{"av zero", "https://www.bilibili.com/video/av0", "", ""},
+		{"lookalike host", "https://notbilibili.com/video/" + bv, "", ""},
+		{"other site", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "", ""},
+		{"empty", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			video, ok := ParseBilibiliURL(tt.url)
+			if ok != (tt.wantID != "") || video.ID() != tt.wantID {
+				t.Fatalf("ParseBilibiliURL(%q) = %+v, %v; want ID %q", tt.url, video, ok, tt.wantID)
+			}
+			if got := (&BilibiliPlatform{}).ExtractVideoID(tt.url); got != tt.wantID {
+				t.Fatalf("ExtractVideoID(%q) = %q, want %q", tt.url, got, tt.wantID)
+			}
+			if ok && video.CanonicalURL() != tt.wantCanonical {
+				t.Fatalf("CanonicalURL() = %q, want %q", video.CanonicalURL(), tt.wantCanonical)
+			}
+		})
+	}
+}
+
+func TestBilibiliAVConversion(t *testing.T) {
+	tests := []struct {
+		aid  uint64
+		bvid string
+	}{
+		{2, "BV1xx411c7mD"},
+		{170001, "BV17x411w7KC"},
+		{111298867365120, "BV1L9Uoa9EUx"},
+	}
+	for _, tt := range tests {
+		if got := AVToBV(tt.aid); got != tt.bvid {
+			t.Errorf("AVToBV(%d) = %q, want %q", tt.aid, got, tt.bvid)
+		}
+		if got, ok := BVToAV(tt.bvid); !ok || got != tt.aid {
+			t.Errorf("BVToAV(%q) = %d, %v; want %d", tt.bvid, got, ok, tt.aid)
+		}
+	}
+	if _, ok := BVToAV("BV1xx411c7m"); ok {
+		t.Error("BVToAV() accepted a truncated ID")
+	}
+}
+
+func TestBilibiliDetectURL(t *testing.T) {
+	b := &BilibiliPlatform{}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.bilibili.com/video/BV1xx411c7mD", true},
+		{"https://space.bilibili.com/2/video", true},
+		{"https://b23.tv/AbCdEf1", true},
+		{"b23.tv/AbCdEf1", true},
+		{"https://bili2233.cn/AbCdEf1", true},
+		{"av170001", true},
+		{"BV1xx411c7mD", true},
+		{"https://notbilibili.com/video/BV1xx411c7mD", false},
+		{"https://example.com/?ref=bilibili.com", false},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", false},
+	}
+	for _, tt := range tests {
+		if got := b.DetectURL(tt.url); got != tt.want {
+			t.Errorf("DetectURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+	if !IsBilibiliShortLink("https://b23.tv/AbCdEf1") || IsBilibiliShortLink("https://www.bilibili.com/video/BV1xx411c7mD") {
+		t.Error("IsBilibiliShortLink() misclassifies links")
+	}
+}
@@ -0,0 +1,90 @@
+package platform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Extensions of links that point straight at a media file
+var (
+	directVideoExts = []string{".mp4", ".m4v", ".mov", ".webm", ".mkv"}
+	directAudioExts = []string{".mp3", ".m4a", ".aac", ".wav", ".flac", ".ogg", ".opus"}
+)
+
+// urlIDLength is the number of hex digits of the URL hash used as video ID
+const urlIDLength = 16
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// GenericPlatform accepts any http(s) link: a page on one of the many sites
+// yt-dlp supports, or a direct link to a media file. It is tried after the
+// platforms with dedicated support.
+type GenericPlatform struct{}
+
+func (g *GenericPlatform) Name() string {
+	return string(Generic)
+}
+
+func (g *GenericPlatform) DetectURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ExtractVideoID returns the ID of a direct media link. Other pages only get
+// their ID once yt-dlp has read them; see GenericVideoID.
+func (g *GenericPlatform) ExtractVideoID(rawURL string) string {
+	if !IsDirectMedia(rawURL) {
+		return ""
+	}
+	return URLVideoID(rawURL)
+}
+
+// IsDirectMedia reports whether rawURL points straight at a media file
+func IsDirectMedia(rawURL string) bool {
+	ext := linkExt(rawURL)
+	return slices.Contains(directVideoExts, ext) || slices.Contains(directAudioExts, ext)
+}
+
+// IsDirectAudio reports whether rawURL points straight at an audio file
+func IsDirectAudio(rawURL string) bool {
+	return slices.Contains(directAudioExts, linkExt(rawURL))
+}
+
+func linkExt(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(path.Ext(u.Path))
+}
+
+// GenericVideoID derives a stable video ID for a link handled by the generic
+// platform from the extractor yt-dlp used and the ID it reported, such as
+// "vimeo-76979871". yt-dlp's own generic extractor names direct links after
+// the file, which is not unique across sites, so those and links without an
+// ID are identified by a hash of the URL instead.
+func GenericVideoID(extractorKey, id, rawURL string) string {
+	extractor := strings.ToLower(unsafeIDChars.ReplaceAllString(extractorKey, ""))
+	id = strings.Trim(unsafeIDChars.ReplaceAllString(id, "_"), "_")
+	if extractor == "" || extractor == "generic" || id == "" {
+		return URLVideoID(rawURL)
+	}
+	return extractor + "-" + id
+}
+
+// URLVideoID derives a video ID from a hash of the link. The fragment is
+// ignored since it never changes what is downloaded.
+func URLVideoID(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		u.Fragment = ""
+		u.Host = strings.ToLower(u.Host)
+		rawURL = u.String()
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	return "url-" + hex.EncodeToString(sum[:])[:urlIDLength]
+}
@@ -0,0 +1,56 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryFallsBackToGeneric(t *testing.T) {
+	r := NewRegistry()
+	tests := []struct {
+		url  string
+		want PlatformName
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", YouTube},
+		{"https://www.bilibili.com/video/BV1xx411c7mD", Bilibili},
+		{"https://vimeo.com/76979871", Generic},
+		{"http://example.com/talks/keynote.mp4?token=abc", Generic},
+		{"dQw4w9WgXcQ", YouTube},
+		{"not a video", Unknown},
+		{"ftp://example.com/video.mp4", Unknown},
+	}
+	for _, tt := range tests {
+		if got := r.DetectPlatformName(tt.url); got != string(tt.want) {
+			t.Errorf("DetectPlatformName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestGenericVideoID(t *testing.T) {
+	if got := GenericVideoID("Vimeo", "76979871", "https://vimeo.com/76979871"); got != "vimeo-76979871" {
+		t.Errorf("GenericVideoID(Vimeo) = %q", got)
+	}
+	if got := GenericVideoID("TwitchVod", "v/12 34", "https://www.twitch.tv/videos/1234"); got != "twitchvod-v_12_34" {
+		t.Errorf("GenericVideoID() did not sanitize the ID: %q", got)
+	}
+
+	a := GenericVideoID("Generic", "keynote", "https://a.example.com/keynote.mp4")
+	b := GenericVideoID("Generic", "keynote", "https://b.example.com/keynote.mp4")
+	if a == b || !strings.HasPrefix(a, "url-") {
+		t.Errorf("direct links with the same file name got IDs %q and %q", a, b)
+	}
+	if got := URLVideoID("https://A.example.com/keynote.mp4#t=30"); got != a {
+		t.Errorf("URLVideoID() depends on host case or fragment: %q != %q", got, a)
+	}
+
+	g := &GenericPlatform{}
+	if got := g.ExtractVideoID("https://a.example.com/keynote.mp4"); got != a {
+		t.Errorf("ExtractVideoID(direct link) = %q, want %q", got, a)
+	}
+	if got := g.ExtractVideoID("https://vimeo.com/76979871"); got != "" {
+		t.Errorf("ExtractVideoID(page) = %q, want it left to yt-dlp", got)
+	}
+	if !IsDirectAudio("https://cdn.example.com/episode-12.MP3?dl=1") || IsDirectAudio("https://cdn.example.com/episode-12.mp4") {
+		t.Error("IsDirectAudio() misclassifies links")
+	}
+}
@@ -6,10 +6,18 @@ type Platform interface {
 	ExtractVideoID(url string) string
 }
 
+// Canonicalizer is implemented by platforms whose video links come in several
+// shapes, to reduce them to one
+type Canonicalizer interface {
+	CanonicalURL(url string) string
+}
+
 type PlatformName string
 
 const (
 	YouTube  PlatformName = "youtube"
 	Bilibili PlatformName = "bilibili"
+	Generic  PlatformName = "generic" // any other page yt-dlp supports
+	Local    PlatformName = "local"   // imported from a file on disk
 	Unknown  PlatformName = "unknown"
 )
@@ -3,6 +3,8 @@ package platform
 var registry = []Platform{
 	&YouTubePlatform{},
 	&BilibiliPlatform{},
+	// Generic accepts every http(s) link, so it must come last
+	&GenericPlatform{},
 }
 
 type Registry struct {
@@ -40,6 +42,15 @@ func (r *Registry) ExtractVideoID(url string) string {
 	return p.ExtractVideoID(url)
 }
 
+// CanonicalURL returns the canonical form of a video link, or url itself
+// when its platform has none
+func (r *Registry) CanonicalURL(url string) string {
+	if c, ok := r.Detect(url).(Canonicalizer); ok {
+		return c.CanonicalURL(url)
+	}
+	return url
+}
+
 func (r *Registry) GetPlatform(name string) Platform {
 	for _, p := range r.platforms {
 		if p.Name() == name {
@@ -3,43 +3,170 @@ package platform
 import (
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type YouTubePlatform struct{}
 
+// youTubeIDPattern matches the 11-character ID of a YouTube video
+var youTubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// youTubeTimePattern matches t= values such as 90, 90s, 1m30s and 1h2m3s
+var youTubeTimePattern = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`)
+
+// youTubeIDPaths are the path prefixes followed by a video ID
+var youTubeIDPaths = []string{"/shorts/", "/live/", "/embed/", "/v/", "/e/"}
+
+// YouTubeVideo is a link to a YouTube video reduced to what identifies it
+type YouTubeVideo struct {
+	ID string
+	// Start is the offset in seconds the link starts playback at, from its
+	// t= or start= parameter
+	Start int
+}
+
+// CanonicalURL returns the watch page URL of the video
+func (v YouTubeVideo) CanonicalURL() string {
+	return "https://www.youtube.com/watch?v=" + v.ID
+}
+
 func (y *YouTubePlatform) Name() string {
 	return string(YouTube)
 }
 
+// DetectURL reports whether rawURL is on a YouTube host, which includes
+// channel and playlist pages, or is a bare video ID
 func (y *YouTubePlatform) DetectURL(rawURL string) bool {
-	u, err := url.Parse(rawURL)
-	if err == nil && u.Host != "" {
-		host := strings.ToLower(u.Host)
-		if strings.Contains(host, "youtube.com") || strings.Contains(host, "youtu.be") {
-			return true
+	rawURL = strings.TrimSpace(rawURL)
+	if youTubeIDPattern.MatchString(rawURL) {
+		return true
+	}
+	u, ok := parseLink(rawURL)
+	return ok && isYouTubeHost(u.Hostname())
+}
+
+func (y *YouTubePlatform) ExtractVideoID(rawURL string) string {
+	video, _ := ParseYouTubeURL(rawURL)
+	return video.ID
+}
+
+// CanonicalURL returns the watch page URL of the video rawURL links to, or
+// rawURL itself when it does not link to a single video
+func (y *YouTubePlatform) CanonicalURL(rawURL string) string {
+	video, ok := ParseYouTubeURL(rawURL)
+	if !ok {
+		return rawURL
+	}
+	return video.CanonicalURL()
+}
+
+// ParseYouTubeURL extracts the video a YouTube link points at. It accepts
+// watch pages in any query order, youtu.be links, shorts, live, embed and
+// legacy /v/ paths on the www, m, music and nocookie hosts, links without a
+// scheme, and bare video IDs.
+func ParseYouTubeURL(rawURL string) (YouTubeVideo, bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	if youTubeIDPattern.MatchString(rawURL) {
+		return YouTubeVideo{ID: rawURL}, true
+	}
+
+	u, ok := parseLink(rawURL)
+	if !ok {
+		return YouTubeVideo{}, false
+	}
+	host := u.Hostname()
+	query := u.Query()
+
+	var id string
+	switch {
+	case host == "youtu.be" || strings.HasSuffix(host, ".youtu.be"):
+		id, _, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	case isYouTubeHost(host):
+		if u.Path == "/watch" || u.Path == "/watch/" {
+			id = query.Get("v")
+			break
+		}
+		if u.Path == "/attribution_link" {
+			// The watch page is URL-encoded in the u parameter
+			if target := query.Get("u"); strings.HasPrefix(target, "/") {
+				return ParseYouTubeURL("https://www.youtube.com" + target)
+			}
+			return YouTubeVideo{}, false
+		}
+		for _, prefix := range youTubeIDPaths {
+			if rest, found := strings.CutPrefix(u.Path, prefix); found {
+				id, _, _ = strings.Cut(rest, "/")
+				break
+			}
+		}
+	default:
+		return YouTubeVideo{}, false
+	}
+	if !youTubeIDPattern.MatchString(id) {
+		return YouTubeVideo{}, false
+	}
+
+	video := YouTubeVideo{ID: id}
+	for _, t := range []string{query.Get("t"), query.Get("start"), fragmentTime(u.Fragment)} {
+		if start, ok := parseYouTubeTime(t); ok {
+			video.Start = start
+			break
 		}
 	}
+	return video, true
+}
 
-	if strings.Contains(rawURL, "youtube.com") || strings.Contains(rawURL, "youtu.be") {
-		return true
+// parseLink parses rawURL as an http(s) URL, assuming https when the scheme
+// is missing as in "youtu.be/ID" or "//player.bilibili.com/..."
+func parseLink(rawURL string) (*url.URL, bool) {
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	} else if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
 	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+	u.Host = strings.ToLower(u.Host)
+	return u, true
+}
 
+func isYouTubeHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range []string{"youtube.com", "youtube-nocookie.com", "youtu.be"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
 	return false
 }
 
-func (y *YouTubePlatform) ExtractVideoID(rawURL string) string {
-	patterns := []string{
-		`(?:youtube\.com\/watch\?v=|youtu\.be\/|youtube\.com\/embed\/)([^&\n?#]+)`,
-		`^([^&\n?#]+)$`,
+// fragmentTime returns the t value of a fragment such as "t=1m30s"
+func fragmentTime(fragment string) string {
+	values, err := url.ParseQuery(fragment)
+	if err != nil {
+		return ""
 	}
+	return values.Get("t")
+}
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if match := re.FindStringSubmatch(rawURL); len(match) > 1 {
-			return match[1]
+// parseYouTubeTime converts a t= value to seconds
+func parseYouTubeTime(t string) (int, bool) {
+	match := youTubeTimePattern.FindStringSubmatch(t)
+	if t == "" || match == nil {
+		return 0, false
+	}
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if match[i+1] != "" {
+			n, err := strconv.Atoi(match[i+1])
+			if err != nil {
+				return 0, false
+			}
+			seconds += n * unit
 		}
 	}
-
-	return ""
+	return seconds, true
 }
@@ -0,0 +1,110 @@
+package platform
+
+import "testing"
+
+func TestParseYouTubeURL(t *testing.T) {
+	const id = "dQw4w9WgXcQ"
+	tests := []struct {
+		name      string
+		url       string
+		wantID    string
+		wantStart int
+	}{
+		{"watch", "https://www.youtube.com/watch?v=" + id, id, 0},
+		{"watch without www", "https://youtube.com/watch?v=" + id, id, 0},
+		{"watch over http", "http://www.youtube.com/watch?v=" + id, id, 0},
+		{"watch without scheme", "www.youtube.com/watch?v=" + id, id, 0},
+		{"v after other parameters", "https://www.youtube.com/watch?feature=share&v=" + id, id, 0},
+		{"playlist parameters", "https://www.youtube.com/watch?v=" + id + "&list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG&index=2", id, 0},
+		{"mobile", "https://m.youtube.com/watch?v=" + id + "&feature=youtu.be", id, 0},
+		{"music", "https://music.youtube.com/watch?v=" + id + "&si=abc", id, 0},
+		{"uppercase host", "https://WWW.YouTube.com/watch?v=" + id, id, 0},
+		{"short link", "https://youtu.be/" + id, id, 0},
+		{"short link with tracking", "https://youtu.be/" + id + "?si=AbCdEf", id, 0},
+		{"short link without scheme", "youtu.be/" + id, id, 0},
+		{"shorts", "https://www.youtube.com/shorts/" + id, id, 0},
+		{"shorts with trailing slash", "https://youtube.com/shorts/" + id + "/?feature=share", id, 0},
+		{"live", "https://www.youtube.com/live/" + id + "?si=xyz", id, 0},
+		{"embed", "https://www.youtube.com/embed/" + id + "?start=42", id, 42},
+		{"nocookie embed", "https://www.youtube-nocookie.com/embed/" + id, id, 0},
+		{"legacy v path", "https://www.youtube.com/v/" + id + "?version=3", id, 0},
+		{"attribution link", "https://www.youtube.com/attribution_link?a=x&u=%2Fwatch%3Fv%3D" + id + "%26feature%3Dshare", id, 0},
+		{"bare ID", id, id, 0},
+		{"bare ID with spaces", "  " + id + "\n", id, 0},
+		{"start in seconds", "https://youtu.be/" + id + "?t=90", id, 90},
+		{"start with s suffix", "https://www.youtube.com/watch?v=" + id + "&t=90s", id, 90},
+		{"start in minutes", "https://www.youtube.com/watch?v=" + id + "&t=1m30s", id, 90},
+		{"start in hours", "https://www.youtube.com/watch?v=" + id + "&t=1h2m3s", id, 3723},
+		{"start in fragment", "https://www.youtube.com/watch?v=" + id + "#t=2m", id, 120},
+		{"invalid start ignored", "https://www.youtube.com/watch?v=" + id + "&t=soon", id, 0},
+
+		{"channel", "https://www.youtube.com/@gophers/videos", "", 0},
+		{"playlist", "https://www.youtube.com/playlist?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", "", 0},
+		{"watch without v", "https://www.youtube.com/watch?feature=share", "", 0},
+		{"ID too short", "https://www.youtube.com/watch?v=dQw4w9WgXc", "", 0},
+		{"ID too long", "https://youtu.be/dQw4w9WgXcQQ", "", 0},
+		{"ID with invalid characters", "https://www.youtube.com/shorts/dQw4w9WgX.Q", "", 0},
+		{"lookalike host", "https://notyoutube.com/watch?v=" + id, "", 0},
+		{"youtube in path only", "https://example.com/youtube.com/watch?v=" + id, "", 0},
+		{"other site", "https://vimeo.com/76979871", "", 0},
+		{"arbitrary text", "not a video", "", 0},
+		{"empty", "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			video, ok := ParseYouTubeURL(tt.url)
+			if ok != (tt.wantID != "") || video.ID != tt.wantID || video.Start != tt.wantStart {
+				t.Fatalf("ParseYouTubeURL(%q) = %+v, %v; want ID %q, start %d", tt.url, video, ok, tt.wantID, tt.wantStart)
+			}
+			if got := (&YouTubePlatform{}).ExtractVideoID(tt.url); got != tt.wantID {
+				t.Fatalf("ExtractVideoID(%q) = %q, want %q", tt.url, got, tt.wantID)
+			}
+			if ok {
+				if got, want := video.CanonicalURL(), "https://www.youtube.com/watch?v="+id; got != want {
+					t.Fatalf("CanonicalURL() = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestYouTubeDetectURL(t *testing.T) {
+	y := &YouTubePlatform{}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/@gophers/videos", true},
+		{"https://www.youtube.com/playlist?list=PL123", true},
+		{"https://music.youtube.com/browse/MPREb_123", true},
+		{"youtu.be/dQw4w9WgXcQ", true},
+		{"dQw4w9WgXcQ", true},
+		{"https://notyoutube.com/watch?v=dQw4w9WgXcQ", false},
+		{"https://example.com/?ref=youtube.com", false},
+		{"https://www.bilibili.com/video/BV1xx411c7mD", false},
+		{"youtube", false},
+	}
+	for _, tt := range tests {
+		if got := y.DetectURL(tt.url); got != tt.want {
+			t.Errorf("DetectURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryCanonicalURL(t *testing.T) {
+	r := NewRegistry()
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://youtu.be/dQw4w9WgXcQ?t=42&si=abc", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/@gophers/videos", "https://www.youtube.com/@gophers/videos"},
+		{"https://vimeo.com/76979871", "https://vimeo.com/76979871"},
+	}
+	for _, tt := range tests {
+		if got := r.CanonicalURL(tt.url); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"transcube-webapp/internal/types"
+)
+
+// cookieBrowsers are the browsers yt-dlp can read cookies from
+var cookieBrowsers = []string{"brave", "chrome", "chromium", "edge", "firefox", "opera", "safari", "vivaldi", "whale"}
+
+// cookiesPlatformPattern limits platform names used in cookie file names
+var cookiesPlatformPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ValidatePlatformAuth checks that auth can be handed to yt-dlp
+func ValidatePlatformAuth(auth types.PlatformAuth) error {
+	if auth.CookiesFile != "" && auth.CookiesFromBrowser != "" {
+		return fmt.Errorf("use either a cookies file or a browser, not both")
+	}
+	if auth.CookiesFromBrowser != "" {
+		browser := auth.CookiesFromBrowser
+		if i := strings.IndexAny(browser, "+:"); i >= 0 {
+			browser = browser[:i]
+		}
+		browser = strings.ToLower(browser)
+		if !slices.Contains(cookieBrowsers, browser) {
+			return fmt.Errorf("unsupported browser %q (use one of %s)", browser, strings.Join(cookieBrowsers, ", "))
+		}
+	}
+	return nil
+}
+
+// SetAuth replaces the cookies yt-dlp uses, keyed by platform name
+func (d *Downloader) SetAuth(auth map[string]types.PlatformAuth) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.auth = maps.Clone(auth)
+}
+
+// platformAuth returns the cookies configured for the platform of url
+func (d *Downloader) platformAuth(url string) (string, types.PlatformAuth, bool) {
+	platform := d.DetectPlatform(url)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	auth, ok := d.auth[platform]
+	return platform, auth, ok && (auth.CookiesFile != "" || auth.CookiesFromBrowser != "")
+}
+
+// authArgs returns the yt-dlp arguments that sign in to the platform of url,
+// or nil when no cookies are configured for it
+func (d *Downloader) authArgs(url string) []string {
+	_, auth, ok := d.platformAuth(url)
+	switch {
+	case !ok:
+		return nil
+	case auth.CookiesFile != "":
+		return []string{"--cookies", auth.CookiesFile}
+	default:
+		return []string{"--cookies-from-browser", auth.CookiesFromBrowser}
+	}
+}
+
+// loginError explains an ErrLoginRequired failure for url: either cookies
+// need to be set up for its platform, or the configured ones were rejected
+func (d *Downloader) loginError(url string, err error) error {
+	if !errors.Is(err, ErrLoginRequired) {
+		return err
+	}
+	platform, _, ok := d.platformAuth(url)
+	if ok {
+		return fmt.Errorf("%w: the %s cookies were rejected; they may have expired, sign in again and update them in Settings", ErrLoginRequired, platform)
+	}
+	return fmt.Errorf("%w: %s requires signing in for this video; add cookies for it in Settings", ErrLoginRequired, platform)
+}
+
+// cookiesDir is where imported cookies files are kept, next to settings.json
+func (s *SettingsStore) cookiesDir() string {
+	return filepath.Join(filepath.Dir(s.filePath), "cookies")
+}
+
+// ImportCookies copies the Netscape-format cookies file at src into the
+// config directory, readable by the current user only, and returns the path
+// of the copy. yt-dlp writes refreshed cookies back to the file it is given,
+// so the user's export is never modified.
+func (s *SettingsStore) ImportCookies(platform string, src string) (string, error) {
+	if !cookiesPlatformPattern.MatchString(platform) {
+		return "", fmt.Errorf("invalid platform name: %q", platform)
+	}
+	if err := checkCookiesFile(src); err != nil {
+		return "", err
+	}
+
+	dir := s.cookiesDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create cookies dir: %w", err)
+	}
+	dst := filepath.Join(dir, platform+".txt")
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("read cookies file: %w", err)
+	}
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return "", fmt.Errorf("write cookies file: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return "", fmt.Errorf("write cookies file: %w", err)
+	}
+	return dst, nil
+}
+
+// RemoveCookies deletes the imported cookies file of a platform, if any
+func (s *SettingsStore) RemoveCookies(platform string) error {
+	if !cookiesPlatformPattern.MatchString(platform) {
+		return fmt.Errorf("invalid platform name: %q", platform)
+	}
+	err := os.Remove(filepath.Join(s.cookiesDir(), platform+".txt"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsImportedCookies reports whether path is a cookies file ImportCookies
+// created
+func (s *SettingsStore) IsImportedCookies(path string) bool {
+	return filepath.Dir(path) == s.cookiesDir()
+}
+
+// checkCookiesFile verifies that path holds cookies in the Netscape format
+// browsers' export extensions and yt-dlp use
+func checkCookiesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open cookies file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Cookies with an empty value end in a tab, so only the line ending
+		// is trimmed
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "# Netscape HTTP Cookie File") || strings.HasPrefix(line, "# HTTP Cookie File") {
+			return nil
+		}
+		// #HttpOnly_ marks a cookie line, not a comment
+		if strings.TrimSpace(line) == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#HttpOnly_")) {
+			continue
+		}
+		if len(strings.Split(line, "\t")) == 7 {
+			return nil
+		}
+		break
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read cookies file: %w", err)
+	}
+	return fmt.Errorf("%s is not a Netscape-format cookies file", filepath.Base(path))
+}
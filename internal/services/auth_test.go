@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestDownloaderAuthArgs(t *testing.T) {
+	d := NewDownloader(nil)
+	d.SetAuth(map[string]types.PlatformAuth{
+		"youtube":  {CookiesFile: "/config/cookies/youtube.txt"},
+		"bilibili": {CookiesFromBrowser: "firefox"},
+	})
+
+	if got := d.authArgs("https://www.youtube.com/watch?v=abc"); !slices.Equal(got, []string{"--cookies", "/config/cookies/youtube.txt"}) {
+		t.Errorf("authArgs(youtube) = %v", got)
+	}
+	if got := d.authArgs("https://www.bilibili.com/video/BV1xx411c7mD"); !slices.Equal(got, []string{"--cookies-from-browser", "firefox"}) {
+		t.Errorf("authArgs(bilibili) = %v", got)
+	}
+	if got := d.authArgs("https://example.com/video.mp4"); got != nil {
+		t.Errorf("authArgs(unknown) = %v, want none", got)
+	}
+}
+
+func TestLoginRequiredErrors(t *testing.T) {
+	output := "ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users. Use --cookies-from-browser or --cookies for the authentication."
+	if err := classifyYtDlpOutput(output); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("classifyYtDlpOutput() = %v, want ErrLoginRequired", err)
+	}
+	if err := classifyYtDlpOutput("ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video"); !errors.Is(err, ErrVideoPrivate) {
+		t.Fatalf("private videos should stay ErrVideoPrivate, got %v", err)
+	}
+
+	d := NewDownloader(nil)
+	url := "https://www.youtube.com/watch?v=abc"
+	missing := d.loginError(url, ErrLoginRequired)
+	d.SetAuth(map[string]types.PlatformAuth{"youtube": {CookiesFromBrowser: "chrome"}})
+	expired := d.loginError(url, ErrLoginRequired)
+
+	for _, err := range []error{missing, expired} {
+		if ErrorCodeOf(err) != types.ErrorCodeLoginRequired {
+			t.Errorf("ErrorCodeOf(%v) = %s", err, ErrorCodeOf(err))
+		}
+	}
+	if !strings.Contains(missing.Error(), "add cookies") || !strings.Contains(expired.Error(), "expired") {
+		t.Errorf("login errors do not tell missing (%v) from expired (%v) cookies", missing, expired)
+	}
+}
+
+func TestValidatePlatformAuth(t *testing.T) {
+	valid := []types.PlatformAuth{
+		{},
+		{CookiesFile: "cookies.txt"},
+		{CookiesFromBrowser: "firefox"},
+		{CookiesFromBrowser: "Chrome+gnomekeyring:Profile 1"},
+	}
+	for _, auth := range valid {
+		if err := ValidatePlatformAuth(auth); err != nil {
+			t.Errorf("ValidatePlatformAuth(%+v) error = %v", auth, err)
+		}
+	}
+	invalid := []types.PlatformAuth{
+		{CookiesFile: "cookies.txt", CookiesFromBrowser: "firefox"},
+		{CookiesFromBrowser: "netscape"},
+		{CookiesFromBrowser: ":default"},
+	}
+	for _, auth := range invalid {
+		if err := ValidatePlatformAuth(auth); err == nil {
+			t.Errorf("ValidatePlatformAuth(%+v) accepted invalid auth", auth)
+		}
+	}
+}
+
+func TestImportCookies(t *testing.T) {
+	store := &SettingsStore{filePath: filepath.Join(t.TempDir(), "settings.json")}
+	src := filepath.Join(t.TempDir(), "export.txt")
+	cookies := "# Netscape HTTP Cookie File\n.youtube.com\tTRUE\t/\tTRUE\t0\tPREF\t\n"
+	if err := os.WriteFile(src, []byte(cookies), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := store.ImportCookies("youtube", src)
+	if err != nil {
+		t.Fatalf("ImportCookies() error = %v", err)
+	}
+	if !store.IsImportedCookies(path) || store.IsImportedCookies(src) {
+		t.Fatalf("IsImportedCookies() does not recognise the imported copy %s", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("imported cookies have mode %v, want 0600", perm)
+	}
+
+	// A header-less export is accepted as long as it holds cookie lines
+	if err := os.WriteFile(src, []byte("#HttpOnly_.bilibili.com\tTRUE\t/\tFALSE\t0\tSESSDATA\tabc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ImportCookies("bilibili", src); err != nil {
+		t.Errorf("ImportCookies() rejected HttpOnly cookie line: %v", err)
+	}
+
+	if err := os.WriteFile(src, []byte(`{"cookies": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ImportCookies("youtube", src); err == nil {
+		t.Error("ImportCookies() accepted a JSON file")
+	}
+	if _, err := store.ImportCookies("../youtube", src); err == nil {
+		t.Error("ImportCookies() accepted a platform name with a path")
+	}
+
+	if err := store.RemoveCookies("youtube"); err != nil {
+		t.Fatalf("RemoveCookies() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cookies file still exists after RemoveCookies: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"transcube-webapp/internal/types"
+)
+
+// CaptionsFile is the name the downloaded caption track is saved under in
+// the task directory
+const CaptionsFile = "captions.vtt"
+
+// SubtitleFormat is one format a subtitle track is offered in
+type SubtitleFormat struct {
+	Ext  string `json:"ext"`
+	URL  string `json:"url"`
+	Name string `json:"name,omitempty"`
+}
+
+// nonCaptionTracks are listed among subtitles by some extractors but do not
+// contain a transcript of the video
+var nonCaptionTracks = []string{"live_chat", "danmaku", "comments"}
+
+// SelectCaptions picks the caption track of the video to use as its
+// transcript in lang, or returns nil when there is none. Subtitles uploaded
+// with the video win over automatic captions. YouTube lists machine
+// translations of its automatic captions into every language, so only the
+// track in the video's original language is accepted.
+func SelectCaptions(info *VideoInfo, lang string) *types.Captions {
+	if info == nil || lang == "" {
+		return nil
+	}
+	lang = strings.ToLower(lang)
+
+	if track := matchCaptionTrack(info.Subtitles, lang); track != "" {
+		return &types.Captions{Lang: track, Source: types.TranscriptSourceOfficial}
+	}
+
+	hasOriginal := false
+	for key := range info.AutomaticCaptions {
+		if strings.HasSuffix(key, "-orig") {
+			hasOriginal = true
+		}
+		if strings.EqualFold(key, lang+"-orig") {
+			return &types.Captions{Lang: key, Source: types.TranscriptSourceAuto}
+		}
+	}
+	if !hasOriginal {
+		for key := range info.AutomaticCaptions {
+			if strings.EqualFold(key, lang) {
+				return &types.Captions{Lang: key, Source: types.TranscriptSourceAuto}
+			}
+		}
+	}
+	return nil
+}
+
+// matchCaptionTrack returns the track in lang, preferring an exact match over
+// a regional variant such as en-US or zh-Hans
+func matchCaptionTrack(tracks map[string][]SubtitleFormat, lang string) string {
+	keys := make([]string, 0, len(tracks))
+	for key := range tracks {
+		if !slices.Contains(nonCaptionTracks, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if strings.EqualFold(key, lang) {
+			return key
+		}
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(strings.ToLower(key), lang+"-") {
+			return key
+		}
+	}
+	return ""
+}
+
+// CaptionsMatch reports whether a caption track can serve as the transcript
+// in lang
+func CaptionsMatch(captions *types.Captions, lang string) bool {
+	if captions == nil || lang == "" {
+		return false
+	}
+	track := strings.ToLower(captions.Lang)
+	lang = strings.ToLower(lang)
+	return track == lang || strings.HasPrefix(track, lang+"-")
+}
+
+// DownloadCaptions saves the given caption track of the video at url as
+// CaptionsFile in outputDir
+func (d *Downloader) DownloadCaptions(ctx context.Context, url string, outputDir string, captions *types.Captions) error {
+	slog.Info("Downloading captions", "url", url, "lang", captions.Lang, "source", captions.Source)
+
+	ytDlpPath, err := d.pathFinder.FindExecutable("yt-dlp")
+	if err != nil {
+		slog.Error("yt-dlp not found", "error", err)
+		return fmt.Errorf("%w: yt-dlp not found: %v", ErrDependencyMissing, err)
+	}
+
+	writeFlag := "--write-subs"
+	if captions.Source == types.TranscriptSourceAuto {
+		writeFlag = "--write-auto-subs"
+	}
+
+	// yt-dlp names the file captions.<lang>.vtt
+	stem := strings.TrimSuffix(CaptionsFile, filepath.Ext(CaptionsFile))
+	cmd := commandContext(ctx, ytDlpPath, append(d.ytDlpArgs(url),
+		"--skip-download",
+		writeFlag,
+		"--sub-langs", "^"+regexp.QuoteMeta(captions.Lang)+"$",
+		"--sub-format", "vtt/best",
+		"--convert-subs", "vtt",
+		"--no-playlist",
+		"-o", filepath.Join(outputDir, stem+".%(ext)s"),
+		url,
+	)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Error("Caption download failed", "error", err, "output", string(output))
+		return d.parseError(url, err, output)
+	}
+
+	written := filepath.Join(outputDir, fmt.Sprintf("%s.%s.vtt", stem, captions.Lang))
+	if err := os.Rename(written, filepath.Join(outputDir, CaptionsFile)); err != nil {
+		return fmt.Errorf("captions were not written: %w", err)
+	}
+
+	if logErr := d.storage.SaveLog(outputDir, "download", fmt.Sprintf("Saved %s captions (%s)", captions.Source, captions.Lang)); logErr != nil {
+		slog.Warn("save download log", "error", logErr)
+	}
+	return nil
+}
+
+// ConvertCaptionsFile converts the WebVTT captions at vttPath to an SRT
+// transcript at srtPath
+func ConvertCaptionsFile(vttPath, srtPath string) error {
+	in, err := os.Open(vttPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			slog.Warn("close captions file", "path", vttPath, "error", err)
+		}
+	}()
+
+	tmp := srtPath + ".tmp"
+	removeTmp := func() {
+		if err := os.Remove(tmp); err != nil {
+			slog.Warn("remove temp transcript", "path", tmp, "error", err)
+		}
+	}
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := ConvertVTTToSRT(in, out); err != nil {
+		if closeErr := out.Close(); closeErr != nil {
+			slog.Warn("close temp transcript", "path", tmp, "error", closeErr)
+		}
+		removeTmp()
+		return fmt.Errorf("convert captions: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		removeTmp()
+		return err
+	}
+	return os.Rename(tmp, srtPath)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestConvertVTTToSRT(t *testing.T) {
+	vtt := `WEBVTT
+Kind: captions
+Language: en
+
+STYLE
+::cue { color: white }
+
+NOTE rolling auto captions
+
+00:00.000 --> 00:02.500 align:start position:0%
+hello<00:00:00.500><c> world</c>
+
+00:00:02.500 --> 00:00:02.510 align:start position:0%
+hello world
+
+intro-3
+00:00:02.510 --> 00:00:05.000 align:start position:0%
+hello world
+fish &amp; chips
+
+1:02:03.004 --> 1:02:04.005
+<i>Bye</i>
+`
+	want := `1
+00:00:00,000 --> 00:00:02,500
+hello world
+
+2
+00:00:02,510 --> 00:00:05,000
+fish & chips
+
+3
+01:02:03,004 --> 01:02:04,005
+Bye
+
+`
+	var out bytes.Buffer
+	if err := ConvertVTTToSRT(strings.NewReader(vtt), &out); err != nil {
+		t.Fatalf("ConvertVTTToSRT() error = %v", err)
+	}
+	if out.String() != want {
+		t.Fatalf("ConvertVTTToSRT() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestSelectCaptions(t *testing.T) {
+	track := []SubtitleFormat{{Ext: "vtt"}}
+	tests := []struct {
+		name string
+		info VideoInfo
+		lang string
+		want *types.Captions
+	}{
+		{
+			name: "uploaded subtitles win",
+			info: VideoInfo{
+				Subtitles:         map[string][]SubtitleFormat{"en": track, "live_chat": track},
+				AutomaticCaptions: map[string][]SubtitleFormat{"en-orig": track},
+			},
+			lang: "en",
+			want: &types.Captions{Lang: "en", Source: types.TranscriptSourceOfficial},
+		},
+		{
+			name: "regional variant",
+			info: VideoInfo{Subtitles: map[string][]SubtitleFormat{"zh-Hant": track, "zh-Hans": track}},
+			lang: "zh",
+			want: &types.Captions{Lang: "zh-Hans", Source: types.TranscriptSourceOfficial},
+		},
+		{
+			name: "original automatic captions",
+			info: VideoInfo{AutomaticCaptions: map[string][]SubtitleFormat{"en-orig": track, "en": track, "ja": track}},
+			lang: "en",
+			want: &types.Captions{Lang: "en-orig", Source: types.TranscriptSourceAuto},
+		},
+		{
+			name: "translated automatic captions are ignored",
+			info: VideoInfo{AutomaticCaptions: map[string][]SubtitleFormat{"en-orig": track, "en": track, "ja": track}},
+			lang: "ja",
+			want: nil,
+		},
+		{
+			name: "automatic captions without original marker",
+			info: VideoInfo{AutomaticCaptions: map[string][]SubtitleFormat{"ja": track}},
+			lang: "ja",
+			want: &types.Captions{Lang: "ja", Source: types.TranscriptSourceAuto},
+		},
+		{
+			name: "danmaku is not a transcript",
+			info: VideoInfo{Subtitles: map[string][]SubtitleFormat{"danmaku": track}},
+			lang: "zh",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectCaptions(&tt.info, tt.lang)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("SelectCaptions() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && !CaptionsMatch(got, tt.lang) {
+				t.Fatalf("CaptionsMatch(%+v, %q) = false", got, tt.lang)
+			}
+		})
+	}
+}
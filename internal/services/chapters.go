@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"transcube-webapp/internal/types"
+)
+
+// ChaptersFile is the name the video's chapters are saved under in the task
+// directory
+const ChaptersFile = "chapters.json"
+
+// minDescriptionChapters is the fewest timestamps a description must list to
+// be read as chapters, the same rule YouTube applies
+const minDescriptionChapters = 3
+
+// VideoChapter is a chapter as yt-dlp reports it
+type VideoChapter struct {
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Title     string  `json:"title"`
+}
+
+// Description lines with a timestamp before or after the title, such as
+// "00:00 Intro", "1:02:03 - Q&A", "(4:05) Demo", "0:00 - 1:30 Opening" or
+// "Wrap-up 12:30"
+var (
+	leadingTimestampPattern  = regexp.MustCompile(`^[\p{Pd}*•▶►]?\s*[(\[]?((?:\d{1,2}:)?\d{1,2}:\d{2})(?:\s*[\p{Pd}~]\s*(?:\d{1,2}:)?\d{1,2}:\d{2})?[)\]]?\s*[\p{Pd}:|.)]?\s*(.+)$`)
+	trailingTimestampPattern = regexp.MustCompile(`^(.*\S)\s+[(\[]?((?:\d{1,2}:)?\d{1,2}:\d{2})[)\]]?$`)
+	srtTimePattern           = regexp.MustCompile(`^(\d+):(\d{2}):(\d{2})(?:[,.](\d{1,3}))?$`)
+)
+
+// ExtractChapters returns the chapters of the video: those yt-dlp reports,
+// or else the ones listed with timestamps in the description
+func ExtractChapters(info *VideoInfo) []types.Chapter {
+	if len(info.Chapters) == 0 {
+		return ParseDescriptionChapters(info.Description, info.Duration)
+	}
+	chapters := make([]types.Chapter, 0, len(info.Chapters))
+	for i, c := range info.Chapters {
+		title := strings.TrimSpace(c.Title)
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		chapters = append(chapters, types.Chapter{Title: title, Start: c.StartTime, End: c.EndTime})
+	}
+	fillChapterEnds(chapters, info.Duration)
+	return chapters
+}
+
+// ParseDescriptionChapters reads chapters from the timestamps in a video
+// description. As on YouTube the list only counts when it starts at 0:00, has
+// at least three entries in ascending order; otherwise the timestamps are
+// taken to be incidental and nil is returned. duration ends the last chapter.
+func ParseDescriptionChapters(description string, duration float64) []types.Chapter {
+	var chapters []types.Chapter
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		start, title, ok := parseChapterLine(line)
+		if !ok {
+			continue
+		}
+		if len(chapters) == 0 && start != 0 {
+			// A list has to open with 0:00; earlier timestamps are not
+			// part of it
+			continue
+		}
+		if len(chapters) > 0 && start <= chapters[len(chapters)-1].Start {
+			// The list is over, or the timestamps were not a list
+			break
+		}
+		chapters = append(chapters, types.Chapter{Title: title, Start: start})
+	}
+	if len(chapters) < minDescriptionChapters {
+		return nil
+	}
+	fillChapterEnds(chapters, duration)
+	return chapters
+}
+
+func parseChapterLine(line string) (float64, string, bool) {
+	var timestamp, title string
+	if match := leadingTimestampPattern.FindStringSubmatch(line); match != nil {
+		timestamp, title = match[1], match[2]
+	} else if match := trailingTimestampPattern.FindStringSubmatch(line); match != nil {
+		title, timestamp = match[1], match[2]
+	} else {
+		return 0, "", false
+	}
+	title = strings.TrimSpace(strings.Trim(strings.TrimSpace(title), "-–—:|"))
+	if title == "" {
+		return 0, "", false
+	}
+
+	seconds := 0
+	parts := strings.Split(timestamp, ":")
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || (i > 0 && n > 59) {
+			return 0, "", false
+		}
+		seconds = seconds*60 + n
+	}
+	return float64(seconds), title, true
+}
+
+// fillChapterEnds sets missing end times to the start of the next chapter,
+// and of the last one to the duration of the video
+func fillChapterEnds(chapters []types.Chapter, duration float64) {
+	for i := range chapters {
+		if chapters[i].End > chapters[i].Start {
+			continue
+		}
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+		} else if duration > chapters[i].Start {
+			chapters[i].End = duration
+		}
+	}
+}
+
+// ChapterAt returns the index of the chapter playing at the given second, or
+// -1 when there is none
+func ChapterAt(chapters []types.Chapter, seconds float64) int {
+	for i := len(chapters) - 1; i >= 0; i-- {
+		if seconds >= chapters[i].Start {
+			return i
+		}
+	}
+	return -1
+}
+
+// SRTSeconds converts an SRT timestamp such as "00:01:02,500" to seconds
+func SRTSeconds(timestamp string) (float64, bool) {
+	match := srtTimePattern.FindStringSubmatch(strings.TrimSpace(timestamp))
+	if match == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+	total := float64(hours*3600 + minutes*60 + seconds)
+	if match[4] != "" {
+		millis, _ := strconv.Atoi((match[4] + "00")[:3])
+		total += float64(millis) / 1000
+	}
+	return total, true
+}
+
+// SaveChapters atomically writes the chapters to ChaptersFile in dir
+func SaveChapters(dir string, chapters []types.Chapter) error {
+	if chapters == nil {
+		chapters = []types.Chapter{}
+	}
+	if err := writeJSONFile(filepath.Join(dir, ChaptersFile), chapters, "chapters"); err != nil {
+		return fmt.Errorf("failed to save chapters: %w", err)
+	}
+	return nil
+}
+
+// LoadChapters reads the chapters saved by SaveChapters. A missing file
+// yields an error os.IsNotExist reports.
+func LoadChapters(dir string) ([]types.Chapter, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ChaptersFile))
+	if err != nil {
+		return nil, err
+	}
+	var chapters []types.Chapter
+	if err := json.Unmarshal(data, &chapters); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ChaptersFile, err)
+	}
+	return chapters, nil
+}
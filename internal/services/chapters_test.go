@@ -0,0 +1,142 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestParseDescriptionChapters(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        []types.Chapter
+	}{
+		{
+			name:        "leading timestamps",
+			description: "A talk about Go.\n\n00:00 Intro\n01:30 - Generics\n1:02:03 | Q&A\n\nFollow us!",
+			want: []types.Chapter{
+				{Title: "Intro", Start: 0, End: 90},
+				{Title: "Generics", Start: 90, End: 3723},
+				{Title: "Q&A", Start: 3723, End: 4000},
+			},
+		},
+		{
+			name:        "trailing and bracketed timestamps",
+			description: "Opening 0:00\n(2:05) Demo\n▶ 10:00 — Wrap-up",
+			want: []types.Chapter{
+				{Title: "Opening", Start: 0, End: 125},
+				{Title: "Demo", Start: 125, End: 600},
+				{Title: "Wrap-up", Start: 600, End: 4000},
+			},
+		},
+		{
+			name:        "ranges",
+			description: "0:00 - 1:00 Opening\n1:00 - 5:00 Middle\n5:00 - 9:00 End",
+			want: []types.Chapter{
+				{Title: "Opening", Start: 0, End: 60},
+				{Title: "Middle", Start: 60, End: 300},
+				{Title: "End", Start: 300, End: 4000},
+			},
+		},
+		{
+			name:        "list ends at a timestamp out of order",
+			description: "0:00 Intro\n1:00 Body\n2:00 Outro\nBack live at 1:30",
+			want: []types.Chapter{
+				{Title: "Intro", Start: 0, End: 60},
+				{Title: "Body", Start: 60, End: 120},
+				{Title: "Outro", Start: 120, End: 4000},
+			},
+		},
+		{"too few", "0:00 Intro\n5:00 Outro", nil},
+		{"not starting at zero", "0:30 Intro\n1:00 Body\n2:00 Outro", nil},
+		{"incidental timestamp", "Recorded live at 19:30 on Friday.", nil},
+		{"invalid seconds", "0:00 Intro\n1:75 Body\n2:00 Middle", nil},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDescriptionChapters(tt.description, 4000); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDescriptionChapters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractChaptersPrefersYtDlp(t *testing.T) {
+	info := &VideoInfo{
+		Duration:    300,
+		Description: "0:00 From the description\n1:00 B\n2:00 C",
+		Chapters: []VideoChapter{
+			{StartTime: 0, EndTime: 100, Title: "Start"},
+			{StartTime: 100, Title: " "},
+		},
+	}
+	want := []types.Chapter{
+		{Title: "Start", Start: 0, End: 100},
+		{Title: "Chapter 2", Start: 100, End: 300},
+	}
+	if got := ExtractChapters(info); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractChapters() = %+v, want %+v", got, want)
+	}
+
+	info.Chapters = nil
+	if got := ExtractChapters(info); len(got) != 3 || got[0].Title != "From the description" {
+		t.Errorf("ExtractChapters() without yt-dlp chapters = %+v", got)
+	}
+}
+
+func TestChapterAt(t *testing.T) {
+	chapters := []types.Chapter{{Title: "A", Start: 5}, {Title: "B", Start: 60}}
+	for _, tt := range []struct {
+		seconds float64
+		want    int
+	}{{0, -1}, {5, 0}, {59.9, 0}, {60, 1}, {1000, 1}} {
+		if got := ChapterAt(chapters, tt.seconds); got != tt.want {
+			t.Errorf("ChapterAt(%v) = %d, want %d", tt.seconds, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		in   string
+		want float64
+		ok   bool
+	}{{"00:01:02,500", 62.5, true}, {"01:00:00.05", 3600.05, true}, {"00:00:07", 7, true}, {"1:02", 0, false}} {
+		if got, ok := SRTSeconds(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("SRTSeconds(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSaveAndLoadChapters(t *testing.T) {
+	dir := t.TempDir()
+	chapters := []types.Chapter{{Title: "Intro", Start: 0, End: 30}}
+	if err := SaveChapters(dir, chapters); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadChapters(dir)
+	if err != nil || !reflect.DeepEqual(got, chapters) {
+		t.Errorf("LoadChapters() = %+v, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ChaptersFile+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+
+	if err := SaveChapters(dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadChapters(dir); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("LoadChapters() after saving none = %#v, %v; want an empty list", got, err)
+	}
+}
+
+func TestChapterOutline(t *testing.T) {
+	outline := chapterOutline([]types.Chapter{{Title: "Intro", Start: 0}, {Title: "Q&A", Start: 3723}})
+	if !strings.Contains(outline, "\n00:00 Intro\n1:02:03 Q&A") {
+		t.Errorf("chapterOutline() = %q", outline)
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+const checkpointsFile = "checkpoints.json"
+
+// Checkpoint records the result of a completed pipeline stage: the files it
+// consumed and produced, the parameters it ran with and the tool versions
+// used. A rerun can skip the stage while the checkpoint is still current.
+type Checkpoint struct {
+	Stage        types.TaskStatus  `json:"stage"`
+	Params       map[string]string `json:"params"`
+	Inputs       []Artifact        `json:"inputs,omitempty"`
+	Artifacts    []Artifact        `json:"artifacts"`
+	ToolVersions map[string]string `json:"toolVersions,omitempty"`
+	CompletedAt  time.Time         `json:"completedAt"`
+}
+
+// Artifact identifies a file inside the task directory
+type Artifact struct {
+	Path    string    `json:"path"` // relative to the task directory
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	SHA256  string    `json:"sha256"`
+}
+
+// NewArtifact describes the file at rel inside taskDir, hashing its content
+func NewArtifact(taskDir, rel string) (Artifact, error) {
+	path := filepath.Join(taskDir, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+	return Artifact{
+		Path:    rel,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		SHA256:  sum,
+	}, nil
+}
+
+// matches reports whether the file on disk is still the recorded one. The
+// size and modification time are checked first so that multi-gigabyte media
+// files are only re-hashed when they were touched.
+func (a Artifact) matches(taskDir string) bool {
+	path := filepath.Join(taskDir, a.Path)
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != a.Size {
+		return false
+	}
+	if info.ModTime().Equal(a.ModTime) {
+		return true
+	}
+	sum, err := fileSHA256(path)
+	return err == nil && sum == a.SHA256
+}
+
+// IsCurrent reports whether the checkpoint still describes the task
+// directory: the stage would run with the same parameters on the same input
+// files, and every artifact it produced is unchanged.
+func (c *Checkpoint) IsCurrent(taskDir string, params map[string]string, inputs []string) bool {
+	if c == nil || !maps.Equal(c.Params, params) || len(c.Inputs) != len(inputs) {
+		return false
+	}
+
+	recorded := make(map[string]Artifact, len(c.Inputs))
+	for _, input := range c.Inputs {
+		recorded[input.Path] = input
+	}
+	for _, rel := range inputs {
+		input, ok := recorded[rel]
+		if !ok || !input.matches(taskDir) {
+			return false
+		}
+	}
+
+	if len(c.Artifacts) == 0 {
+		return false
+	}
+	for _, artifact := range c.Artifacts {
+		if !artifact.matches(taskDir) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewCheckpoint builds a checkpoint for a stage that just completed
+func NewCheckpoint(taskDir string, stage types.TaskStatus, params map[string]string, inputs, outputs []string, toolVersions map[string]string) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		Stage:        stage,
+		Params:       params,
+		ToolVersions: toolVersions,
+		CompletedAt:  time.Now(),
+	}
+	for _, rel := range inputs {
+		artifact, err := NewArtifact(taskDir, rel)
+		if err != nil {
+			return nil, fmt.Errorf("describe input %s: %w", rel, err)
+		}
+		cp.Inputs = append(cp.Inputs, artifact)
+	}
+	for _, rel := range outputs {
+		artifact, err := NewArtifact(taskDir, rel)
+		if err != nil {
+			return nil, fmt.Errorf("describe artifact %s: %w", rel, err)
+		}
+		cp.Artifacts = append(cp.Artifacts, artifact)
+	}
+	return cp, nil
+}
+
+// LoadCheckpoints reads the checkpoints recorded in the task directory
+func (s *Storage) LoadCheckpoints(taskDir string) (map[types.TaskStatus]*Checkpoint, error) {
+	data, err := os.ReadFile(filepath.Join(taskDir, checkpointsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[types.TaskStatus]*Checkpoint{}, nil
+		}
+		return nil, err
+	}
+
+	checkpoints := map[types.TaskStatus]*Checkpoint{}
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
+
+// SaveCheckpoint records or replaces the checkpoint for its stage
+func (s *Storage) SaveCheckpoint(taskDir string, cp *Checkpoint) error {
+	checkpoints, err := s.LoadCheckpoints(taskDir)
+	if err != nil {
+		slog.Warn("Discarding unreadable checkpoints", "taskDir", taskDir, "error", err)
+		checkpoints = map[types.TaskStatus]*Checkpoint{}
+	}
+	checkpoints[cp.Stage] = cp
+	return s.writeCheckpoints(taskDir, checkpoints)
+}
+
+// RemoveCheckpoints drops the checkpoints of the given stages so they run
+// again on the next pass
+func (s *Storage) RemoveCheckpoints(taskDir string, stages ...types.TaskStatus) error {
+	checkpoints, err := s.LoadCheckpoints(taskDir)
+	if err != nil {
+		checkpoints = map[types.TaskStatus]*Checkpoint{}
+	}
+	for _, stage := range stages {
+		delete(checkpoints, stage)
+	}
+	return s.writeCheckpoints(taskDir, checkpoints)
+}
+
+func (s *Storage) writeCheckpoints(taskDir string, checkpoints map[types.TaskStatus]*Checkpoint) error {
+	return writeJSONFile(filepath.Join(taskDir, checkpointsFile), checkpoints, "checkpoints")
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			slog.Error("close hashed file", "error", err)
+		}
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestCheckpointIsCurrent(t *testing.T) {
+	params := map[string]string{"model": "base", "language": "en"}
+	tests := []struct {
+		name    string
+		change  func(t *testing.T, dir string)
+		params  map[string]string
+		inputs  []string
+		current bool
+	}{
+		{name: "unchanged", current: true},
+		{
+			name: "touched with same content",
+			change: func(t *testing.T, dir string) {
+				later := time.Now().Add(time.Hour)
+				if err := os.Chtimes(filepath.Join(dir, "transcript.srt"), later, later); err != nil {
+					t.Fatal(err)
+				}
+			},
+			current: true,
+		},
+		{
+			name: "rewritten with same size",
+			change: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "transcript.srt")
+				writeTestFile(t, path, "1\nhullo\n")
+				later := time.Now().Add(time.Hour)
+				if err := os.Chtimes(path, later, later); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "input resized",
+			change: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "audio.m4a"), "longer audio")
+			},
+		},
+		{
+			name: "artifact missing",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "transcript.srt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{name: "params changed", params: map[string]string{"model": "large", "language": "en"}},
+		{name: "different inputs", inputs: []string{"video.mp4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, filepath.Join(dir, "audio.m4a"), "audio")
+			writeTestFile(t, filepath.Join(dir, "transcript.srt"), "1\nhello\n")
+			cp, err := NewCheckpoint(dir, types.TaskStatusTranscribing, params, []string{"audio.m4a"}, []string{"transcript.srt"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.change != nil {
+				tt.change(t, dir)
+			}
+			runParams, inputs := params, []string{"audio.m4a"}
+			if tt.params != nil {
+				runParams = tt.params
+			}
+			if tt.inputs != nil {
+				inputs = tt.inputs
+			}
+			if got := cp.IsCurrent(dir, runParams, inputs); got != tt.current {
+				t.Errorf("IsCurrent() = %v, want %v", got, tt.current)
+			}
+		})
+	}
+
+	var missing *Checkpoint
+	if missing.IsCurrent(t.TempDir(), params, nil) {
+		t.Error("a missing checkpoint is current")
+	}
+}
+
+func TestSaveAndRemoveCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "audio.m4a"), "audio")
+	storage := NewStorage(t.TempDir())
+
+	for _, stage := range []types.TaskStatus{types.TaskStatusDownloading, types.TaskStatusTranscribing} {
+		cp, err := NewCheckpoint(dir, stage, map[string]string{}, nil, []string{"audio.m4a"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.SaveCheckpoint(dir, cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := storage.RemoveCheckpoints(dir, types.TaskStatusTranscribing); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoints, err := storage.LoadCheckpoints(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 1 || checkpoints[types.TaskStatusDownloading] == nil {
+		t.Errorf("checkpoints = %v, want only the download stage", checkpoints)
+	}
+	if _, err := os.Stat(filepath.Join(dir, checkpointsFile+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary checkpoints file left behind: %v", err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
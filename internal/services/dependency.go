@@ -1,26 +1,35 @@
 package services
 
 import (
+	"os/exec"
+	"strings"
+	"sync"
+
 	"transcube-webapp/internal/types"
 	"transcube-webapp/internal/utils"
 )
 
 type DependencyChecker struct {
 	pathFinder *utils.PathFinder
+
+	mu       sync.Mutex
+	versions map[string]string
 }
 
 func NewDependencyChecker() *DependencyChecker {
 	return &DependencyChecker{
 		pathFinder: utils.NewPathFinder(),
+		versions:   make(map[string]string),
 	}
 }
 
 // Check verifies all required dependencies are installed
 func (d *DependencyChecker) Check() types.DependencyStatus {
 	return types.DependencyStatus{
-		YtDlp:  d.isInstalled("yt-dlp"),
-		FFmpeg: d.isInstalled("ffmpeg"),
-		Yap:    d.isInstalled("yap"),
+		YtDlp:   d.isInstalled("yt-dlp"),
+		FFmpeg:  d.isInstalled("ffmpeg"),
+		FFprobe: d.isInstalled("ffprobe"),
+		Yap:     d.isInstalled("yap"),
 	}
 }
 
@@ -30,12 +39,42 @@ func (d *DependencyChecker) isInstalled(cmd string) bool {
 	return err == nil
 }
 
+// ToolVersion returns the first line of the tool's version output, or an
+// empty string when the tool is missing. Results are cached for the lifetime
+// of the process.
+func (d *DependencyChecker) ToolVersion(name string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if version, ok := d.versions[name]; ok {
+		return version
+	}
+
+	path, err := d.pathFinder.FindExecutable(name)
+	if err != nil {
+		return ""
+	}
+
+	flag := "--version"
+	if name == "ffmpeg" || name == "ffprobe" {
+		flag = "-version"
+	}
+	output, err := exec.Command(path, flag).Output()
+	if err != nil {
+		return ""
+	}
+
+	version := strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	d.versions[name] = version
+	return version
+}
+
 // GetInstallCommand returns the installation command for missing dependencies
 func (d *DependencyChecker) GetInstallCommand(dep string) string {
 	switch dep {
 	case "yt-dlp":
 		return "brew install yt-dlp"
-	case "ffmpeg":
+	case "ffmpeg", "ffprobe":
 		return "brew install ffmpeg"
 	case "yap":
 		return "brew install yap"
@@ -1,22 +1,46 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	neturl "net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"transcube-webapp/internal/platform"
+	"transcube-webapp/internal/types"
 	"transcube-webapp/internal/utils"
 )
 
+// Format selectors of the built-in format presets and audio parameters used
+// by ExtractAudio. They are recorded in the download checkpoint, so changing
+// them makes existing downloads stale.
+const (
+	mp4FormatSelector   = "bestvideo[height<=1080][vcodec^=avc1]+bestaudio/bestvideo[height<=1080][vcodec^=h264]+bestaudio/bestvideo[height<=1080]+bestaudio/best[height<=1080]"
+	webmFormatSelector  = "bestvideo[height<=1080]+bestaudio/best[height<=1080]"
+	audioFormatSelector = "bestaudio[ext=m4a]/bestaudio/best"
+	audioSampleRate     = "16000"
+	audioChannels       = "1"
+)
+
 type Downloader struct {
 	storage          *Storage
 	pathFinder       *utils.PathFinder
 	platformRegistry *platform.Registry
+
+	// mu guards the yt-dlp options set from settings
+	mu      sync.RWMutex
+	auth    map[string]types.PlatformAuth
+	network types.NetworkSettings
+
+	infoCache *infoCache
 }
 
 func NewDownloader(storage *Storage) *Downloader {
@@ -24,41 +48,57 @@ func NewDownloader(storage *Storage) *Downloader {
 		storage:          storage,
 		pathFinder:       utils.NewPathFinder(),
 		platformRegistry: platform.NewRegistry(),
+		infoCache:        newInfoCache(infoCacheTTL),
 	}
 }
 
 // VideoInfo represents the metadata returned by yt-dlp
 type VideoInfo struct {
-	ID          string  `json:"id"`
-	Title       string  `json:"title"`
-	Channel     string  `json:"channel,omitempty"`
-	Uploader    string  `json:"uploader,omitempty"`
-	Duration    float64 `json:"duration"`
-	Thumbnail   string  `json:"thumbnail"`
-	Description string  `json:"description"`
-	UploadDate  string  `json:"upload_date"`
-	ChannelID   string  `json:"channel_id,omitempty"`
-	ViewCount   int64   `json:"view_count,omitempty"`
-	LikeCount   int64   `json:"like_count,omitempty"`
-	Timestamp   int64   `json:"timestamp,omitempty"`
-	ReleaseTS   int64   `json:"release_timestamp,omitempty"`
-}
-
-// GetVideoInfo fetches video metadata using yt-dlp
-func (d *Downloader) GetVideoInfo(url string) (*VideoInfo, error) {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Channel     string         `json:"channel,omitempty"`
+	Uploader    string         `json:"uploader,omitempty"`
+	Duration    float64        `json:"duration"`
+	Thumbnail   string         `json:"thumbnail"`
+	Description string         `json:"description"`
+	Tags        []string       `json:"tags,omitempty"`
+	UploadDate  string         `json:"upload_date"`
+	ChannelID   string         `json:"channel_id,omitempty"`
+	ViewCount   int64          `json:"view_count,omitempty"`
+	LikeCount   int64          `json:"like_count,omitempty"`
+	Timestamp   int64          `json:"timestamp,omitempty"`
+	ReleaseTS   int64          `json:"release_timestamp,omitempty"`
+	Chapters    []VideoChapter `json:"chapters,omitempty"`
+	// ExtractorKey names the yt-dlp extractor that read the page
+	ExtractorKey string `json:"extractor_key,omitempty"`
+	// Subtitle tracks by language: uploaded with the video, and generated by
+	// the platform
+	Subtitles         map[string][]SubtitleFormat `json:"subtitles,omitempty"`
+	AutomaticCaptions map[string][]SubtitleFormat `json:"automatic_captions,omitempty"`
+
+	// raw is the complete yt-dlp output the fields above were read from
+	raw []byte
+}
+
+// RefreshVideoInfo fetches video metadata using yt-dlp, replacing what the
+// cache holds for the URL
+func (d *Downloader) RefreshVideoInfo(ctx context.Context, url string) (*VideoInfo, error) {
 	slog.Debug("Fetching video info with yt-dlp", "url", url)
 
 	ytDlpPath, err := d.pathFinder.FindExecutable("yt-dlp")
 	if err != nil {
 		slog.Error("yt-dlp not found", "error", err)
-		return nil, fmt.Errorf("yt-dlp not found: %v", err)
+		return nil, fmt.Errorf("%w: yt-dlp not found: %v", ErrDependencyMissing, err)
 	}
 
-	cmd := exec.Command(ytDlpPath, "--dump-json", "--no-playlist", url)
+	cmd := commandContext(ctx, ytDlpPath, append(d.ytDlpArgs(url), "--dump-json", "--no-playlist", url)...)
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		slog.Error("yt-dlp failed to get video info", "url", url, "error", err)
-		return nil, d.parseError(err)
+		return nil, d.parseError(url, err, nil)
 	}
 
 	var info VideoInfo
@@ -69,113 +109,380 @@ func (d *Downloader) GetVideoInfo(url string) (*VideoInfo, error) {
 
 	slog.Info("Video info retrieved", "id", info.ID, "title", info.Title, "duration", info.Duration)
 
+	info.raw = output
+	d.normalizeVideoInfo(url, &info)
+	d.infoCache.put(d.CanonicalURL(url), &info)
+	return &info, nil
+}
+
+// normalizeVideoInfo fills in what yt-dlp leaves out or names differently
+// per site: the channel and the video ID tasks use
+func (d *Downloader) normalizeVideoInfo(url string, info *VideoInfo) {
 	// Use uploader if channel is empty
 	if info.Channel == "" {
 		info.Channel = info.Uploader
 	}
+	info.ID = d.videoID(url, info.ExtractorKey, info.ID)
+	if d.DetectPlatform(url) == string(platform.Generic) {
+		if info.Channel == "" {
+			info.Channel = urlHost(url)
+		}
+	}
+}
+
+// PlaylistInfo is the flat listing yt-dlp returns for a playlist, channel tab
+// or series. Entries only carry what the listing page shows.
+type PlaylistInfo struct {
+	Type      string          `json:"_type"`
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Channel   string          `json:"channel,omitempty"`
+	Uploader  string          `json:"uploader,omitempty"`
+	ChannelID string          `json:"channel_id,omitempty"`
+	URL       string          `json:"webpage_url,omitempty"`
+	Entries   []PlaylistEntry `json:"entries"`
+}
+
+// PlaylistEntry is one item of a flat playlist listing
+type PlaylistEntry struct {
+	Type       string              `json:"_type"`
+	IEKey      string              `json:"ie_key,omitempty"`
+	ID         string              `json:"id"`
+	URL        string              `json:"url"`
+	Title      string              `json:"title"`
+	Channel    string              `json:"channel,omitempty"`
+	Uploader   string              `json:"uploader,omitempty"`
+	Duration   float64             `json:"duration,omitempty"`
+	Thumbnail  string              `json:"thumbnail,omitempty"`
+	Thumbnails []PlaylistThumbnail `json:"thumbnails,omitempty"`
+}
+
+// PlaylistThumbnail is a thumbnail of a playlist entry; yt-dlp lists them
+// from smallest to largest
+type PlaylistThumbnail struct {
+	URL string `json:"url"`
+}
+
+// IsVideo reports whether the entry points at a single video rather than a
+// nested playlist such as a channel's "Videos" or "Shorts" tab
+func (e PlaylistEntry) IsVideo() bool {
+	if e.Type == "playlist" || strings.HasSuffix(e.IEKey, "Tab") || strings.HasSuffix(e.IEKey, "Playlist") {
+		return false
+	}
+	return e.ID != "" && e.URL != ""
+}
+
+// BestThumbnail returns the largest thumbnail of the entry, if any
+func (e PlaylistEntry) BestThumbnail() string {
+	if e.Thumbnail != "" {
+		return e.Thumbnail
+	}
+	if len(e.Thumbnails) > 0 {
+		return e.Thumbnails[len(e.Thumbnails)-1].URL
+	}
+	return ""
+}
+
+// ListPlaylist lists the entries of a playlist, channel tab or series without
+// resolving each video
+func (d *Downloader) ListPlaylist(ctx context.Context, url string) (*PlaylistInfo, error) {
+	return d.listPlaylist(ctx, url, 0)
+}
+
+// ListRecentUploads lists at most limit of the first entries of a channel
+// tab, which yt-dlp returns newest first
+func (d *Downloader) ListRecentUploads(ctx context.Context, url string, limit int) (*PlaylistInfo, error) {
+	return d.listPlaylist(ctx, url, limit)
+}
+
+func (d *Downloader) listPlaylist(ctx context.Context, url string, limit int) (*PlaylistInfo, error) {
+	slog.Debug("Listing playlist with yt-dlp", "url", url, "limit", limit)
+
+	ytDlpPath, err := d.pathFinder.FindExecutable("yt-dlp")
+	if err != nil {
+		slog.Error("yt-dlp not found", "error", err)
+		return nil, fmt.Errorf("%w: yt-dlp not found: %v", ErrDependencyMissing, err)
+	}
+
+	args := append(d.ytDlpArgs(url), "--flat-playlist", "--dump-single-json")
+	if limit > 0 {
+		args = append(args, "--playlist-end", strconv.Itoa(limit))
+	}
+	cmd := commandContext(ctx, ytDlpPath, append(args, url)...)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		slog.Error("yt-dlp failed to list playlist", "url", url, "error", err)
+		return nil, d.parseError(url, err, nil)
+	}
+
+	var info PlaylistInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		slog.Error("Failed to parse playlist JSON", "error", err)
+		return nil, fmt.Errorf("failed to parse playlist info: %v", err)
+	}
+	if info.Type != "playlist" {
+		return nil, fmt.Errorf("url does not point to a playlist, channel or series: %s", url)
+	}
+	if info.Channel == "" {
+		info.Channel = info.Uploader
+	}
+	for i, entry := range info.Entries {
+		if entry.IsVideo() {
+			info.Entries[i].ID = d.videoID(entry.URL, entry.IEKey, entry.ID)
+		}
+	}
 
+	slog.Info("Playlist listed", "id", info.ID, "title", info.Title, "entries", len(info.Entries))
 	return &info, nil
 }
 
-// DownloadVideo downloads the video file (with audio)
-func (d *Downloader) DownloadVideo(url string, outputDir string) error {
-	slog.Info("Starting video download", "url", url, "outputDir", outputDir)
+// DownloadResult describes the file a download produced
+type DownloadResult struct {
+	// File is the name of the downloaded file in the output directory
+	File string
+	// Format is the yt-dlp format selector that produced it
+	Format string
+}
+
+// DownloadVideo downloads the video file (with audio) in the given format
+// preset. When the preset's format cannot be fetched in its container, its
+// fallback format is tried as WebM. Cancelling ctx kills yt-dlp and any merge
+// process it spawned; partial files are left in place. Transfer progress is
+// streamed to onProgress, which may be nil.
+func (d *Downloader) DownloadVideo(ctx context.Context, url string, outputDir string, preset types.FormatPreset, onProgress ProgressFunc) (*DownloadResult, error) {
+	slog.Info("Starting video download", "url", url, "outputDir", outputDir, "preset", preset.Name)
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		slog.Error("Failed to create output directory", "dir", outputDir, "error", err)
-		return err
+		return nil, err
 	}
 
 	ytDlpPath, err := d.pathFinder.FindExecutable("yt-dlp")
 	if err != nil {
 		slog.Error("yt-dlp not found", "error", err)
-		return fmt.Errorf("yt-dlp not found: %v", err)
+		return nil, fmt.Errorf("%w: yt-dlp not found: %v", ErrDependencyMissing, err)
 	}
 
-	// First attempt: MP4 (best compatibility)
-	mp4Path := filepath.Join(outputDir, "video.mp4")
-	cmdMp4 := exec.Command(ytDlpPath,
-		"-f", "bestvideo[height<=1080][vcodec^=avc1]+bestaudio/bestvideo[height<=1080][vcodec^=h264]+bestaudio/bestvideo[height<=1080]+bestaudio/best[height<=1080]",
-		"--merge-output-format", "mp4",
-		"--continue",
-		"--no-playlist",
-		"-o", mp4Path,
-		url,
-	)
+	// First attempt: the preset's own container (mp4 unless configured)
+	container := presetContainer(preset)
+	file := "video." + container
+	cmd := commandContext(ctx, ytDlpPath, append(d.ytDlpArgs(url), videoDownloadArgs(preset, preset.Format, container, filepath.Join(outputDir, file), url)...)...)
 
-	slog.Debug("Running yt-dlp (mp4) download command")
-	output, err := cmdMp4.CombinedOutput()
+	slog.Debug("Running yt-dlp download command", "container", container)
+	output, err := runWithLines(cmd, ytDlpLineHandler(onProgress))
 	if err == nil {
-		slog.Info("Video downloaded successfully (mp4)", "outputDir", outputDir)
-		if logErr := d.storage.SaveLog(outputDir, "download", "Video downloaded successfully (mp4)"); logErr != nil {
+		if err := d.checkMaxFilesize(outputDir, preset, output); err != nil {
+			return nil, err
+		}
+		slog.Info("Video downloaded successfully", "outputDir", outputDir, "container", container)
+		if logErr := d.storage.SaveLog(outputDir, "download", fmt.Sprintf("Video downloaded successfully (%s, %s)", container, preset.Name)); logErr != nil {
 			slog.Warn("save download log", "error", logErr)
 		}
-		return nil
+		return &DownloadResult{File: file, Format: preset.Format}, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	// A different container cannot help when the video itself is unreachable
+	if classified := classifyYtDlpOutput(string(output)); classified != nil || preset.FallbackFormat == "" || container == "webm" {
+		slog.Error("Video download failed", "container", container, "error", err, "output", string(output))
+		if logErr := d.storage.SaveLog(outputDir, "download", fmt.Sprintf("%s download failed\n%s", strings.ToUpper(container), output)); logErr != nil {
+			slog.Warn("save download log", "error", logErr)
+		}
+		return nil, d.parseError(url, err, output)
 	}
 
 	// Fallback: WebM (more permissive for VP9/Opus)
-	slog.Warn("MP4 download failed; attempting WebM fallback", "error", err)
-	if logErr := d.storage.SaveLog(outputDir, "download", "MP4 failed; attempting WebM fallback\n"+string(output)); logErr != nil {
+	slog.Warn("Download failed; attempting WebM fallback", "container", container, "error", err)
+	if logErr := d.storage.SaveLog(outputDir, "download", fmt.Sprintf("%s failed; attempting WebM fallback\n%s", strings.ToUpper(container), output)); logErr != nil {
 		slog.Warn("save download log", "error", logErr)
 	}
 
-	webmPath := filepath.Join(outputDir, "video.webm")
-	cmdWebm := exec.Command(ytDlpPath,
-		"-f", "bestvideo[height<=1080]+bestaudio/best[height<=1080]",
-		"--merge-output-format", "webm",
-		"--continue",
-		"--no-playlist",
-		"-o", webmPath,
-		url,
-	)
+	file = "video.webm"
+	cmdWebm := commandContext(ctx, ytDlpPath, append(d.ytDlpArgs(url), videoDownloadArgs(preset, preset.FallbackFormat, "webm", filepath.Join(outputDir, file), url)...)...)
 	slog.Debug("Running yt-dlp (webm) download command")
-	output2, err2 := cmdWebm.CombinedOutput()
+	output2, err2 := runWithLines(cmdWebm, ytDlpLineHandler(onProgress))
+	if err2 != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err2 != nil {
 		slog.Error("Video download failed (webm fallback)", "error", err2, "output", string(output2))
 		if logErr := d.storage.SaveLog(outputDir, "download", "WebM fallback failed\n"+string(output2)); logErr != nil {
 			slog.Warn("save download log", "error", logErr)
 		}
-		return d.parseError(err2)
+		return nil, d.parseError(url, err2, output2)
+	}
+	if err := d.checkMaxFilesize(outputDir, preset, output2); err != nil {
+		return nil, err
 	}
 
 	slog.Info("Video downloaded successfully (webm)", "outputDir", outputDir)
-	if logErr := d.storage.SaveLog(outputDir, "download", "Video downloaded successfully (webm)"); logErr != nil {
+	if logErr := d.storage.SaveLog(outputDir, "download", fmt.Sprintf("Video downloaded successfully (webm, %s)", preset.Name)); logErr != nil {
 		slog.Warn("save download log", "error", logErr)
 	}
-	return nil
+	return &DownloadResult{File: file, Format: preset.FallbackFormat}, nil
 }
 
-// parseError parses yt-dlp errors to provide user-friendly messages
-func (d *Downloader) parseError(err error) error {
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		stderr := string(exitErr.Stderr)
+// videoDownloadArgs returns the yt-dlp arguments that download url in format,
+// merged into container at outputPath
+func videoDownloadArgs(preset types.FormatPreset, format, container, outputPath, url string) []string {
+	args := []string{
+		"-f", format,
+		"--merge-output-format", container,
+	}
+	if preset.MaxFilesize != "" {
+		args = append(args, "--max-filesize", preset.MaxFilesize)
+	}
+	return append(args,
+		"--continue",
+		"--newline",
+		"--progress-template", ytDlpProgressTemplate,
+		"--no-playlist",
+		"-o", outputPath,
+		url,
+	)
+}
 
-		// Check for common error patterns
-		if strings.Contains(stderr, "ERROR: Private video") {
-			return fmt.Errorf("video is private")
-		}
-		if strings.Contains(stderr, "ERROR: Video unavailable") {
-			return fmt.Errorf("video is unavailable")
-		}
-		if strings.Contains(stderr, "ERROR: This video is not available") {
-			return fmt.Errorf("video is not available in your region")
-		}
-		if strings.Contains(stderr, "ERROR: Unable to extract video data") {
-			return fmt.Errorf("unable to extract video data")
+// checkMaxFilesize returns ErrFileTooLarge when yt-dlp skipped the download
+// for exceeding the preset's --max-filesize. yt-dlp still exits 0 then,
+// without writing the file.
+func (d *Downloader) checkMaxFilesize(outputDir string, preset types.FormatPreset, output []byte) error {
+	if preset.MaxFilesize == "" || !strings.Contains(string(output), "larger than max-filesize") {
+		return nil
+	}
+	slog.Warn("Download skipped by the size limit", "preset", preset.Name, "maxFilesize", preset.MaxFilesize)
+	if logErr := d.storage.SaveLog(outputDir, "download", "Download skipped by the size limit\n"+string(output)); logErr != nil {
+		slog.Warn("save download log", "error", logErr)
+	}
+	return fmt.Errorf("%w: the video exceeds the %s limit of format preset %q; choose a smaller preset or raise its limit", ErrFileTooLarge, preset.MaxFilesize, preset.Name)
+}
+
+// AudioOnlyFile is the name of the audio track fetched in audio-only mode
+const AudioOnlyFile = "source.m4a"
+
+// DownloadAudio downloads only the audio track of the video as AudioOnlyFile
+// in outputDir, using the preset's format. Tracks in other containers are
+// converted to m4a by yt-dlp. Transfer progress is streamed to onProgress,
+// which may be nil.
+func (d *Downloader) DownloadAudio(ctx context.Context, url string, outputDir string, preset types.FormatPreset, onProgress ProgressFunc) (*DownloadResult, error) {
+	slog.Info("Starting audio-only download", "url", url, "outputDir", outputDir, "preset", preset.Name)
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		slog.Error("Failed to create output directory", "dir", outputDir, "error", err)
+		return nil, err
+	}
+
+	ytDlpPath, err := d.pathFinder.FindExecutable("yt-dlp")
+	if err != nil {
+		slog.Error("yt-dlp not found", "error", err)
+		return nil, fmt.Errorf("%w: yt-dlp not found: %v", ErrDependencyMissing, err)
+	}
+
+	stem := strings.TrimSuffix(AudioOnlyFile, filepath.Ext(AudioOnlyFile))
+	args := append(d.ytDlpArgs(url),
+		"-f", preset.Format,
+		"--extract-audio",
+		"--audio-format", "m4a",
+	)
+	if preset.MaxFilesize != "" {
+		args = append(args, "--max-filesize", preset.MaxFilesize)
+	}
+	args = append(args,
+		"--continue",
+		"--newline",
+		"--progress-template", ytDlpProgressTemplate,
+		"--no-playlist",
+		"-o", filepath.Join(outputDir, stem+".%(ext)s"),
+		url,
+	)
+	cmd := commandContext(ctx, ytDlpPath, args...)
+
+	slog.Debug("Running yt-dlp audio download command")
+	output, err := runWithLines(cmd, ytDlpLineHandler(onProgress))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		if strings.Contains(stderr, "ERROR: Forbidden") || strings.Contains(stderr, "HTTP Error 403") {
-			return fmt.Errorf("access forbidden (403)")
+		slog.Error("Audio download failed", "error", err, "output", string(output))
+		if logErr := d.storage.SaveLog(outputDir, "download", "Audio download failed\n"+string(output)); logErr != nil {
+			slog.Warn("save download log", "error", logErr)
 		}
-		if strings.Contains(stderr, "HTTP Error 410") {
-			return fmt.Errorf("video no longer exists (410)")
+		return nil, d.parseError(url, err, output)
+	}
+	if err := d.checkMaxFilesize(outputDir, preset, output); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Audio downloaded successfully", "outputDir", outputDir)
+	if logErr := d.storage.SaveLog(outputDir, "download", "Audio downloaded successfully (m4a)"); logErr != nil {
+		slog.Warn("save download log", "error", logErr)
+	}
+	return &DownloadResult{File: AudioOnlyFile, Format: preset.Format}, nil
+}
+
+// ytDlpLineHandler forwards yt-dlp progress lines to onProgress and keeps them
+// out of the captured output
+func ytDlpLineHandler(onProgress ProgressFunc) func(string) bool {
+	return func(line string) bool {
+		update, ok := parseYtDlpProgress(line)
+		if ok && onProgress != nil {
+			onProgress(update)
 		}
+		return ok
+	}
+}
 
-		// Extract video ID if present for better error context
-		if match := regexp.MustCompile(`\[youtube\] ([a-zA-Z0-9_-]+):`).FindStringSubmatch(stderr); len(match) > 1 {
-			return fmt.Errorf("failed to process video %s: %v", match[1], err)
+// DownloadParams returns the parameters that determine the output of the
+// download stage for url in the given format preset
+func (d *Downloader) DownloadParams(url string, preset types.FormatPreset) map[string]string {
+	params := map[string]string{
+		"url":             url,
+		"format":          preset.Format,
+		"audioCodec":      "aac",
+		"audioSampleRate": audioSampleRate,
+		"audioChannels":   audioChannels,
+	}
+	if preset.AudioOnly {
+		params["mode"] = string(types.DownloadModeAudio)
+	} else {
+		params["fallbackFormat"] = preset.FallbackFormat
+		if container := presetContainer(preset); container != "mp4" {
+			params["container"] = container
 		}
 	}
+	if preset.MaxFilesize != "" {
+		params["maxFilesize"] = preset.MaxFilesize
+	}
+	return params
+}
+
+// parseError parses yt-dlp errors to provide user-friendly messages. The
+// returned error wraps one of the sentinel errors when the output matches a
+// known failure class. output is the captured yt-dlp output, if any; stderr
+// captured by Output is read from the exit error. url tells which platform's
+// sign-in a login error refers to.
+func (d *Downloader) parseError(url string, err error, output []byte) error {
+	text := string(output)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		text += "\n" + string(exitErr.Stderr)
+	}
+
+	if classified := classifyYtDlpOutput(text); classified != nil {
+		return d.loginError(url, classified)
+	}
+	if strings.Contains(text, "ERROR: Unable to extract video data") {
+		return fmt.Errorf("unable to extract video data")
+	}
+
+	// Extract video ID if present for better error context
+	if match := regexp.MustCompile(`\[youtube\] ([a-zA-Z0-9_-]+):`).FindStringSubmatch(text); len(match) > 1 {
+		return fmt.Errorf("failed to process video %s: %v", match[1], err)
+	}
 
 	return fmt.Errorf("download failed: %v", err)
 }
@@ -185,32 +492,113 @@ func (d *Downloader) DetectPlatform(url string) string {
 	return d.platformRegistry.DetectPlatformName(url)
 }
 
+// urlHost returns the host of a URL without a www. prefix
+func urlHost(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// CanonicalURL reduces a video link to the canonical form of its platform,
+// dropping tracking and playlist parameters
+func (d *Downloader) CanonicalURL(url string) string {
+	return d.platformRegistry.CanonicalURL(url)
+}
+
+// ResolveURL returns the canonical URL of the video a link points at.
+// Short links such as b23.tv only name the video once followed, so yt-dlp
+// resolves them first.
+func (d *Downloader) ResolveURL(ctx context.Context, url string) (string, error) {
+	if !platform.IsBilibiliShortLink(url) {
+		return d.CanonicalURL(url), nil
+	}
+	slog.Debug("Resolving short link with yt-dlp", "url", url)
+
+	ytDlpPath, err := d.pathFinder.FindExecutable("yt-dlp")
+	if err != nil {
+		slog.Error("yt-dlp not found", "error", err)
+		return "", fmt.Errorf("%w: yt-dlp not found: %v", ErrDependencyMissing, err)
+	}
+
+	cmd := commandContext(ctx, ytDlpPath, append(d.ytDlpArgs(url), "--no-playlist", "--skip-download", "--print", "webpage_url", url)...)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		slog.Error("yt-dlp failed to resolve short link", "url", url, "error", err)
+		return "", d.parseError(url, err, nil)
+	}
+
+	var resolved string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			resolved = line
+		}
+	}
+	if resolved == "" || platform.IsBilibiliShortLink(resolved) {
+		return "", fmt.Errorf("could not resolve short link: %s", url)
+	}
+	slog.Info("Short link resolved", "url", url, "resolved", resolved)
+	return d.CanonicalURL(resolved), nil
+}
+
+// videoID returns the ID tasks identify the video at url by. Bilibili links
+// name the part of a multi-part upload, and generic links get an ID that is
+// unique across sites; otherwise yt-dlp's ID is used as is.
+func (d *Downloader) videoID(url, extractorKey, id string) string {
+	switch d.DetectPlatform(url) {
+	case string(platform.Generic):
+		return platform.GenericVideoID(extractorKey, id, url)
+	case string(platform.Bilibili):
+		if video, ok := platform.ParseBilibiliURL(url); ok {
+			return video.ID()
+		}
+	}
+	return id
+}
+
 // ExtractVideoID extracts the video ID from a video URL
 func (d *Downloader) ExtractVideoID(url string) string {
 	return d.platformRegistry.ExtractVideoID(url)
 }
 
-// ExtractAudio extracts audio from video file for transcription
-func (d *Downloader) ExtractAudio(videoPath string, audioPath string) error {
+// ExtractAudio extracts audio from video file for transcription, reporting
+// ffmpeg's position in the input to onProgress
+func (d *Downloader) ExtractAudio(ctx context.Context, videoPath string, audioPath string, onProgress ProgressFunc) error {
 	slog.Info("Extracting audio from video", "videoPath", videoPath, "audioPath", audioPath)
 
 	ffmpegPath, err := d.pathFinder.FindExecutable("ffmpeg")
 	if err != nil {
 		slog.Error("ffmpeg not found", "error", err)
-		return fmt.Errorf("ffmpeg not found: %v", err)
+		return fmt.Errorf("%w: ffmpeg not found: %v", ErrDependencyMissing, err)
 	}
 
-	cmd := exec.Command(ffmpegPath,
+	cmd := commandContext(ctx, ffmpegPath,
 		"-i", videoPath,
 		"-vn", // no video
 		"-acodec", "aac",
-		"-ar", "16000", // 16kHz for transcription
-		"-ac", "1", // mono
+		"-ar", audioSampleRate, // 16kHz for transcription
+		"-ac", audioChannels, // mono
 		"-y", // overwrite output
+		"-progress", "pipe:1",
+		"-nostats",
 		audioPath,
 	)
 
-	output, err := cmd.CombinedOutput()
+	progress := &ffmpegProgress{}
+	output, err := runWithLines(cmd, func(line string) bool {
+		update, consumed, ok := progress.parseLine(line)
+		if ok && onProgress != nil {
+			onProgress(update)
+		}
+		return consumed
+	})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		slog.Error("Audio extraction failed", "error", err, "output", string(output))
 		return fmt.Errorf("failed to extract audio: %v", err)
@@ -0,0 +1,78 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadAudio(t *testing.T) {
+	listing, argsLog := fakeYtDlp(t)
+	if err := os.WriteFile(listing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDownloader(NewStorage(t.TempDir()))
+	dir := t.TempDir()
+
+	preset, _ := FindFormatPreset(nil, AudioFormatPresetName)
+	preset.MaxFilesize = "200M"
+	url := "https://example.com/talk.mp4"
+	result, err := d.DownloadAudio(context.Background(), url, dir, preset, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.File != AudioOnlyFile || result.Format != audioFormatSelector {
+		t.Errorf("DownloadAudio() = %+v", result)
+	}
+
+	data, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := string(data)
+	// A page offering only combined formats still has audio to extract
+	for _, want := range []string{
+		"-f " + audioFormatSelector + " --extract-audio --audio-format m4a --max-filesize 200M",
+		"-o " + filepath.Join(dir, "source.%(ext)s") + " " + url,
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("yt-dlp args %q do not contain %q", args, want)
+		}
+	}
+	if !strings.HasSuffix(audioFormatSelector, "/best") {
+		t.Errorf("audio selector %q has no fallback to a combined format", audioFormatSelector)
+	}
+
+	params := d.DownloadParams(url, preset)
+	if params["mode"] != "audio" || params["maxFilesize"] != "200M" || params["fallbackFormat"] != "" || params["container"] != "" {
+		t.Errorf("DownloadParams() in audio mode = %v", params)
+	}
+}
+
+func TestDownloadOverSizeLimit(t *testing.T) {
+	listing, _ := fakeYtDlp(t)
+	skipped := "[info] abc: Downloading 1 format(s): 137+140\n[download] File is larger than max-filesize (734003200 bytes > 524288000 bytes). Aborting."
+	if err := os.WriteFile(listing, []byte(skipped), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDownloader(NewStorage(t.TempDir()))
+	ctx := context.Background()
+
+	preset, _ := FindFormatPreset(nil, DefaultFormatPresetName)
+	preset.MaxFilesize = "500M"
+	_, err := d.DownloadVideo(ctx, "https://www.youtube.com/watch?v=abc", t.TempDir(), preset, nil)
+	if !errors.Is(err, ErrFileTooLarge) || !strings.Contains(err.Error(), "500M") || IsRetryable(err) {
+		t.Errorf("DownloadVideo() error = %v, want a non-retryable ErrFileTooLarge naming the limit", err)
+	}
+
+	audio, _ := FindFormatPreset(nil, AudioFormatPresetName)
+	audio.MaxFilesize = "20M"
+	if _, err := d.DownloadAudio(ctx, "https://www.youtube.com/watch?v=abc", t.TempDir(), audio, nil); !errors.Is(err, ErrFileTooLarge) || !strings.Contains(err.Error(), "20M") {
+		t.Errorf("DownloadAudio() error = %v, want ErrFileTooLarge naming the limit", err)
+	}
+}
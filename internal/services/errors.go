@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+// Sentinel errors classifying stage failures. Stage implementations wrap
+// them with %w so callers can match with errors.Is and map them to a
+// types.ErrorCode.
+var (
+	ErrVideoPrivate       = errors.New("video is private")
+	ErrGeoBlocked         = errors.New("video is not available in your region")
+	ErrVideoRemoved       = errors.New("video no longer exists (410)")
+	ErrForbidden          = errors.New("access forbidden (403)")
+	ErrLoginRequired      = errors.New("login required")
+	ErrRateLimited        = errors.New("rate limited (429)")
+	ErrNetworkTimeout     = errors.New("network timeout")
+	ErrNetworkError       = errors.New("network error")
+	ErrServiceUnavailable = errors.New("service temporarily unavailable")
+	ErrDependencyMissing  = errors.New("dependency missing")
+	ErrFileTooLarge       = errors.New("file is larger than the size limit")
+	ErrASRFailed          = errors.New("transcription failed")
+	ErrLLMQuota           = errors.New("LLM quota exceeded")
+)
+
+var errorCodes = []struct {
+	err  error
+	code types.ErrorCode
+}{
+	{ErrVideoPrivate, types.ErrorCodePrivate},
+	{ErrGeoBlocked, types.ErrorCodeGeoBlocked},
+	{ErrVideoRemoved, types.ErrorCodeRemoved},
+	{ErrForbidden, types.ErrorCodeForbidden},
+	{ErrLoginRequired, types.ErrorCodeLoginRequired},
+	{ErrRateLimited, types.ErrorCodeRateLimited},
+	{ErrNetworkTimeout, types.ErrorCodeNetworkTimeout},
+	{ErrNetworkError, types.ErrorCodeNetworkError},
+	{ErrServiceUnavailable, types.ErrorCodeServiceUnavailable},
+	{ErrDependencyMissing, types.ErrorCodeDependencyMissing},
+	{ErrFileTooLarge, types.ErrorCodeFileTooLarge},
+	{ErrASRFailed, types.ErrorCodeASRFailed},
+	{ErrLLMQuota, types.ErrorCodeLLMQuota},
+}
+
+// ErrorCodeOf returns the error code for the first sentinel err wraps, or
+// ErrorCodeUnknown
+func ErrorCodeOf(err error) types.ErrorCode {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return types.ErrorCodeUnknown
+}
+
+// IsRetryable reports whether err belongs to a transient class that may
+// succeed when the operation is repeated
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrNetworkTimeout) ||
+		errors.Is(err, ErrNetworkError) ||
+		errors.Is(err, ErrServiceUnavailable)
+}
+
+// ytDlpErrorPatterns maps yt-dlp output fragments to error classes. Order
+// matters: more specific messages come first. YouTube's generic "Video
+// unavailable" and "This video is not available" say nothing about the cause,
+// so only the specific reasons that follow them are matched.
+var ytDlpErrorPatterns = []struct {
+	fragments []string
+	err       error
+}{
+	{[]string{"Private video", "This video is private"}, ErrVideoPrivate},
+	{[]string{"Sign in to confirm", "members-only", "only available to Music Premium members", "only available for registered users", "premium members only", "supporter-only", "for the authentication", "cookies are no longer valid", "login required", "Login required", "need to log in"}, ErrLoginRequired},
+	{[]string{"not available in your country", "not made this video available in your country", "not available from your location", "geo restriction", "geo-restricted"}, ErrGeoBlocked},
+	{[]string{"HTTP Error 410", "This video has been removed", "video has been terminated", "account associated with this video has been terminated", "This video is no longer available"}, ErrVideoRemoved},
+	{[]string{"HTTP Error 429", "Too Many Requests"}, ErrRateLimited},
+	{[]string{"ERROR: Forbidden", "HTTP Error 403"}, ErrForbidden},
+	{[]string{"timed out"}, ErrNetworkTimeout},
+	{[]string{"Connection reset", "Temporary failure in name resolution", "Name or service not known", "nodename nor servname", "Network is unreachable", "IncompleteRead", "Connection refused"}, ErrNetworkError},
+	{[]string{"HTTP Error 500", "HTTP Error 502", "HTTP Error 503", "HTTP Error 504"}, ErrServiceUnavailable},
+}
+
+// classifyYtDlpOutput returns the sentinel matching yt-dlp's output, or nil
+func classifyYtDlpOutput(output string) error {
+	for _, pattern := range ytDlpErrorPatterns {
+		for _, fragment := range pattern.fragments {
+			if strings.Contains(output, fragment) {
+				return pattern.err
+			}
+		}
+	}
+	return nil
+}
+
+// classifyHTTPError maps a failed HTTP round trip to an error class. Timeouts
+// are told apart from refused connections and failed DNS lookups.
+func classifyHTTPError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %v", ErrNetworkTimeout, err)
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return fmt.Errorf("%w: %v", ErrNetworkError, err)
+	}
+	return err
+}
+
+// RetryConfig controls exponential backoff for retryable errors
+type RetryConfig struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	// OnRetry, if set, is called before sleeping ahead of the next attempt
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+// DefaultRetryConfig makes a first attempt and retries up to three times,
+// waiting 1s, 2s and 4s
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts:  4,
+	InitialDelay: time.Second,
+	MaxDelay:     4 * time.Second,
+	Multiplier:   2,
+}
+
+// retryAfter waits out the delay between attempts; tests replace it
+var retryAfter = time.After
+
+// WithRetry calls fn until it succeeds, returns a non-retryable error, the
+// attempts are exhausted or ctx is done. The last error is returned as is so
+// its class is preserved.
+func WithRetry(ctx context.Context, config RetryConfig, fn func() error) error {
+	attempts := config.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := config.InitialDelay
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryable(err) || ctx.Err() != nil || attempt == attempts {
+			return err
+		}
+
+		slog.Warn("Retrying after transient error", "attempt", attempt, "delay", delay, "error", err)
+		if config.OnRetry != nil {
+			config.OnRetry(attempt, err, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-retryAfter(delay):
+		}
+
+		delay = time.Duration(float64(delay) * config.Multiplier)
+		if config.MaxDelay > 0 && delay > config.MaxDelay {
+			delay = config.MaxDelay
+		}
+	}
+	return err
+}
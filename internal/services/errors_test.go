@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+// instantRetries makes WithRetry skip its waits for the rest of the test
+func instantRetries(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	original := retryAfter
+	retryAfter = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	t.Cleanup(func() { retryAfter = original })
+	return &waits
+}
+
+func TestWithRetryDefaultSchedule(t *testing.T) {
+	waits := instantRetries(t)
+
+	calls := 0
+	var retried []int
+	config := DefaultRetryConfig
+	config.OnRetry = func(attempt int, err error, delay time.Duration) {
+		retried = append(retried, attempt)
+	}
+	err := WithRetry(context.Background(), config, func() error {
+		calls++
+		return fmt.Errorf("%w: attempt %d", ErrRateLimited, calls)
+	})
+
+	if calls != 4 {
+		t.Errorf("fn called %d times, want a first attempt and 3 retries", calls)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}; !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waited %v, want %v", *waits, want)
+	}
+	if !reflect.DeepEqual(retried, []int{1, 2, 3}) {
+		t.Errorf("OnRetry called for attempts %v", retried)
+	}
+	if !errors.Is(err, ErrRateLimited) || err.Error() != "rate limited (429): attempt 4" {
+		t.Errorf("WithRetry() = %v, want the last error", err)
+	}
+}
+
+func TestWithRetryStops(t *testing.T) {
+	waits := instantRetries(t)
+
+	calls := 0
+	err := WithRetry(context.Background(), DefaultRetryConfig, func() error {
+		calls++
+		if calls == 1 {
+			return ErrServiceUnavailable
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("after a success: err %v, %d calls; want nil, 2", err, calls)
+	}
+
+	calls = 0
+	err = WithRetry(context.Background(), DefaultRetryConfig, func() error {
+		calls++
+		return fmt.Errorf("%w: gone", ErrVideoRemoved)
+	})
+	if !errors.Is(err, ErrVideoRemoved) || calls != 1 {
+		t.Errorf("after a permanent error: err %v, %d calls; want it returned at once", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = WithRetry(ctx, DefaultRetryConfig, func() error {
+		calls++
+		cancel()
+		return ErrNetworkTimeout
+	})
+	if !errors.Is(err, ErrNetworkTimeout) || calls != 1 {
+		t.Errorf("after cancellation: err %v, %d calls; want 1 call", err, calls)
+	}
+
+	config := RetryConfig{MaxAttempts: 5, InitialDelay: time.Second, MaxDelay: 3 * time.Second, Multiplier: 2}
+	*waits = nil
+	_ = WithRetry(context.Background(), config, func() error { return ErrNetworkTimeout })
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}; !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits capped at MaxDelay = %v, want %v", *waits, want)
+	}
+}
+
+func TestClassifyYtDlpOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   error
+	}{
+		{"ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video", ErrVideoPrivate},
+		{"ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.", ErrLoginRequired},
+		{"ERROR: [youtube] abc: Video unavailable. The uploader has not made this video available in your country", ErrGeoBlocked},
+		{"ERROR: [niconico] sm9: The video is not available from your location due to geo restriction", ErrGeoBlocked},
+		{"ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader", ErrVideoRemoved},
+		{"ERROR: [youtube] abc: Video unavailable. This video is no longer available because the YouTube account associated with this video has been terminated.", ErrVideoRemoved},
+		{"ERROR: unable to download video data: HTTP Error 410: Gone", ErrVideoRemoved},
+		{"ERROR: [youtube] abc: Video unavailable", nil},
+		{"ERROR: [youtube] abc: This video is not available", nil},
+		{"ERROR: unable to download webpage: HTTP Error 429: Too Many Requests", ErrRateLimited},
+		{"ERROR: unable to download video data: HTTP Error 403: Forbidden", ErrForbidden},
+		{"ERROR: [youtube] abc: Unable to download API page: The read operation timed out", ErrNetworkTimeout},
+		{"ERROR: <urlopen error [Errno -3] Temporary failure in name resolution>", ErrNetworkError},
+		{"ERROR: <urlopen error [Errno 111] Connection refused>", ErrNetworkError},
+		{"ERROR: unable to download webpage: HTTP Error 503: Service Unavailable", ErrServiceUnavailable},
+		{"ERROR: Unsupported URL: https://example.com/", nil},
+	}
+	for _, tt := range tests {
+		if got := classifyYtDlpOutput(tt.output); got != tt.want {
+			t.Errorf("classifyYtDlpOutput(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want types.ErrorCode
+	}{
+		{fmt.Errorf("download: %w", ErrFileTooLarge), types.ErrorCodeFileTooLarge},
+		{fmt.Errorf("%w: yt-dlp not found", ErrDependencyMissing), types.ErrorCodeDependencyMissing},
+		{fmt.Errorf("%w: connection refused", ErrNetworkError), types.ErrorCodeNetworkError},
+		{ErrRateLimited, types.ErrorCodeRateLimited},
+		{errors.New("exit status 1"), types.ErrorCodeUnknown},
+		{nil, types.ErrorCodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := ErrorCodeOf(tt.err); got != tt.want {
+			t.Errorf("ErrorCodeOf(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+	if IsRetryable(ErrFileTooLarge) {
+		t.Error("an oversized file was treated as retryable")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyHTTPError(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"deadline", fmt.Errorf("Post: %w", context.DeadlineExceeded), ErrNetworkTimeout},
+		{"timeout", &url.Error{Op: "Post", URL: "https://api.example.com", Err: timeoutError{}}, ErrNetworkTimeout},
+		{"connection refused", &url.Error{Op: "Post", URL: "https://api.example.com", Err: refused}, ErrNetworkError},
+		{"dns", &url.Error{Op: "Post", URL: "https://api.example.com", Err: &net.DNSError{Err: "no such host", Name: "api.example.com"}}, ErrNetworkError},
+		{"other", errors.New("unsupported protocol scheme"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyHTTPError(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Fatalf("classifyHTTPError() = %v, want the error unchanged", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("classifyHTTPError() = %v, want %v", got, tt.want)
+			}
+			if tt.want == ErrNetworkError && errors.Is(got, ErrNetworkTimeout) {
+				t.Fatalf("classifyHTTPError() labelled %v a timeout", tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+// Events emitted to the frontend
+const (
+	// EventTaskUpdated carries the full task after any change to its status,
+	// progress, error or metadata
+	EventTaskUpdated = "task:updated"
+	// EventTaskLog carries a TaskLogEntry for each line written to a task log
+	EventTaskLog = "task:log"
+)
+
+// taskEventInterval is the minimum time between two progress-only updates of
+// the same task. Status changes are never delayed.
+const taskEventInterval = 250 * time.Millisecond
+
+// EmitFunc delivers an event to the frontend
+type EmitFunc func(event string, data any)
+
+// TaskLogEntry is the payload of EventTaskLog
+type TaskLogEntry struct {
+	TaskID string    `json:"taskId"`
+	Type   string    `json:"type"`
+	Line   string    `json:"line"`
+	Time   time.Time `json:"time"`
+}
+
+// TaskEvents publishes task changes. Progress updates arriving faster than
+// taskEventInterval are coalesced so that only the latest snapshot is sent
+// once the interval has passed; any other change flushes immediately.
+type TaskEvents struct {
+	mu       sync.Mutex
+	emit     EmitFunc
+	lastSent map[string]time.Time
+	pending  map[string]*types.Task
+	timers   map[string]*time.Timer
+
+	// now and afterFunc are replaced in tests to control the clock
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) *time.Timer
+}
+
+func NewTaskEvents() *TaskEvents {
+	return &TaskEvents{
+		lastSent: make(map[string]time.Time),
+		pending:  make(map[string]*types.Task),
+		timers:   make(map[string]*time.Timer),
+
+		now:       time.Now,
+		afterFunc: time.AfterFunc,
+	}
+}
+
+// SetEmitter sets the function events are delivered through. Events published
+// before an emitter is set are dropped.
+func (e *TaskEvents) SetEmitter(emit EmitFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.emit = emit
+}
+
+// TaskUpdated publishes a snapshot of the task. Pass throttle for
+// high-frequency progress updates.
+func (e *TaskEvents) TaskUpdated(task *types.Task, throttle bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.emit == nil {
+		return
+	}
+
+	if throttle {
+		wait := taskEventInterval - e.now().Sub(e.lastSent[task.ID])
+		if wait > 0 {
+			e.pending[task.ID] = task
+			if e.timers[task.ID] == nil {
+				id := task.ID
+				e.timers[id] = e.afterFunc(wait, func() { e.flush(id) })
+			}
+			return
+		}
+	}
+
+	e.stopTimerLocked(task.ID)
+	delete(e.pending, task.ID)
+	e.sendLocked(task)
+}
+
+// TaskLog publishes a line written to a task log
+func (e *TaskEvents) TaskLog(entry TaskLogEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.emit != nil {
+		e.emit(EventTaskLog, entry)
+	}
+}
+
+// Forget drops the throttling state kept for a task
+func (e *TaskEvents) Forget(taskID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopTimerLocked(taskID)
+	delete(e.pending, taskID)
+	delete(e.lastSent, taskID)
+}
+
+func (e *TaskEvents) flush(taskID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.timers, taskID)
+	task, ok := e.pending[taskID]
+	if !ok || e.emit == nil {
+		return
+	}
+	delete(e.pending, taskID)
+	e.sendLocked(task)
+}
+
+func (e *TaskEvents) sendLocked(task *types.Task) {
+	e.lastSent[task.ID] = e.now()
+	e.emit(EventTaskUpdated, task)
+}
+
+func (e *TaskEvents) stopTimerLocked(taskID string) {
+	if timer, ok := e.timers[taskID]; ok {
+		timer.Stop()
+		delete(e.timers, taskID)
+	}
+}
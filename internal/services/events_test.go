@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+// fakeEventClock drives a TaskEvents without real time passing. Scheduled
+// flushes only run when fire is called.
+type fakeEventClock struct {
+	now       time.Time
+	scheduled []func()
+	waits     []time.Duration
+}
+
+func newTestTaskEvents(t *testing.T) (*TaskEvents, *fakeEventClock, *[]*types.Task) {
+	t.Helper()
+	clock := &fakeEventClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	e := NewTaskEvents()
+	e.now = func() time.Time { return clock.now }
+	e.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		clock.waits = append(clock.waits, d)
+		clock.scheduled = append(clock.scheduled, f)
+		timer := time.NewTimer(time.Hour)
+		t.Cleanup(func() { timer.Stop() })
+		return timer
+	}
+
+	var sent []*types.Task
+	e.SetEmitter(func(event string, data any) {
+		if event != EventTaskUpdated {
+			t.Fatalf("unexpected event %s", event)
+		}
+		sent = append(sent, data.(*types.Task))
+	})
+	return e, clock, &sent
+}
+
+// fire runs the flushes scheduled so far
+func (c *fakeEventClock) fire() {
+	scheduled := c.scheduled
+	c.scheduled = nil
+	for _, f := range scheduled {
+		f()
+	}
+}
+
+func progressSnapshot(progress int, status types.TaskStatus) *types.Task {
+	return &types.Task{ID: "task-1", Status: status, Progress: progress}
+}
+
+func TestTaskEventsCoalescesProgressBursts(t *testing.T) {
+	e, clock, sent := newTestTaskEvents(t)
+
+	e.TaskUpdated(progressSnapshot(10, types.TaskStatusDownloading), true)
+	if len(*sent) != 1 {
+		t.Fatalf("first update sent %d events, want it sent at once", len(*sent))
+	}
+
+	clock.now = clock.now.Add(50 * time.Millisecond)
+	for progress := 11; progress <= 20; progress++ {
+		e.TaskUpdated(progressSnapshot(progress, types.TaskStatusDownloading), true)
+	}
+	if len(*sent) != 1 {
+		t.Fatalf("burst sent %d events before the interval passed, want 1", len(*sent))
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != taskEventInterval-50*time.Millisecond {
+		t.Fatalf("scheduled flushes after %v, want one after the rest of the interval", clock.waits)
+	}
+
+	clock.now = clock.now.Add(200 * time.Millisecond)
+	clock.fire()
+	if len(*sent) != 2 || (*sent)[1].Progress != 20 {
+		t.Fatalf("trailing flush sent %v, want the latest progress 20", progressOf(*sent))
+	}
+
+	// Nothing is pending any more, so a late flush sends nothing
+	clock.fire()
+	if len(*sent) != 2 {
+		t.Errorf("flush without pending updates sent %v", progressOf(*sent))
+	}
+
+	// Once the interval has passed the next update goes straight out
+	clock.now = clock.now.Add(taskEventInterval)
+	e.TaskUpdated(progressSnapshot(30, types.TaskStatusDownloading), true)
+	if len(*sent) != 3 || len(clock.scheduled) != 0 {
+		t.Errorf("update after the interval: sent %v, %d flushes scheduled", progressOf(*sent), len(clock.scheduled))
+	}
+}
+
+func TestTaskEventsStatusChangeFlushesImmediately(t *testing.T) {
+	e, clock, sent := newTestTaskEvents(t)
+
+	e.TaskUpdated(progressSnapshot(10, types.TaskStatusSummarizing), true)
+	clock.now = clock.now.Add(10 * time.Millisecond)
+	e.TaskUpdated(progressSnapshot(90, types.TaskStatusSummarizing), true)
+	e.TaskUpdated(progressSnapshot(100, types.TaskStatusDone), false)
+
+	if len(*sent) != 2 || (*sent)[1].Status != types.TaskStatusDone {
+		t.Fatalf("sent %v, want the status change right after the first update", progressOf(*sent))
+	}
+
+	// The throttled snapshot was superseded and must not follow the final state
+	clock.fire()
+	if last := (*sent)[len(*sent)-1]; len(*sent) != 2 || last.Status != types.TaskStatusDone {
+		t.Errorf("stale progress sent after the final state: %v", progressOf(*sent))
+	}
+}
+
+func progressOf(tasks []*types.Task) []int {
+	progress := make([]int, len(tasks))
+	for i, task := range tasks {
+		progress[i] = task.Progress
+	}
+	return progress
+}
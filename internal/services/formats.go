@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"transcube-webapp/internal/types"
+)
+
+// Names of the built-in format presets
+const (
+	DefaultFormatPresetName = "1080p h264"
+	AudioFormatPresetName   = "audio-only"
+)
+
+// byteSizePattern matches the sizes yt-dlp accepts for --max-filesize and
+// --limit-rate
+var byteSizePattern = regexp.MustCompile(`^\d+(\.\d+)?[kKmMgGtT]?$`)
+
+// DefaultFormatPresets returns the built-in format presets, with
+// DefaultFormatPresetName first
+func DefaultFormatPresets() []types.FormatPreset {
+	return []types.FormatPreset{
+		{
+			Name:           DefaultFormatPresetName,
+			Format:         mp4FormatSelector,
+			FallbackFormat: webmFormatSelector,
+			Container:      "mp4",
+		},
+		{
+			Name:           "480p small",
+			Format:         "bestvideo[height<=480][vcodec^=avc1]+bestaudio[ext=m4a]/bestvideo[height<=480]+bestaudio/best[height<=480]",
+			FallbackFormat: "bestvideo[height<=480]+bestaudio/best[height<=480]",
+			Container:      "mp4",
+		},
+		{
+			Name:           "best",
+			Format:         "bestvideo+bestaudio/best",
+			FallbackFormat: "bestvideo+bestaudio/best",
+			Container:      "mp4",
+		},
+		{
+			Name:      AudioFormatPresetName,
+			Format:    audioFormatSelector,
+			AudioOnly: true,
+		},
+	}
+}
+
+// FindFormatPreset returns the preset called name. The built-in presets are
+// looked up when presets does not define it.
+func FindFormatPreset(presets []types.FormatPreset, name string) (types.FormatPreset, bool) {
+	for _, list := range [][]types.FormatPreset{presets, DefaultFormatPresets()} {
+		if i := slices.IndexFunc(list, func(p types.FormatPreset) bool { return p.Name == name }); i >= 0 {
+			return list[i], true
+		}
+	}
+	return types.FormatPreset{}, false
+}
+
+// SelectFormatPreset returns the preset a download uses: the task's own
+// preset, or else defaultName from settings. A download mode wins over a
+// preset that disagrees with it, so an audio-only download gets the audio-only
+// preset and the other way round. The mode from settings only applies to
+// tasks that chose neither a preset nor a mode.
+func SelectFormatPreset(presets []types.FormatPreset, defaultName string, defaultMode types.DownloadMode, taskPreset string, taskMode types.DownloadMode) types.FormatPreset {
+	name := taskPreset
+	if name == "" {
+		name = defaultName
+	}
+	preset, ok := FindFormatPreset(presets, name)
+	if !ok {
+		preset, _ = FindFormatPreset(nil, DefaultFormatPresetName)
+	}
+
+	mode := taskMode
+	if mode == "" && taskPreset == "" && !preset.AudioOnly && defaultMode == types.DownloadModeAudio {
+		mode = types.DownloadModeAudio
+	}
+	switch {
+	case mode == types.DownloadModeAudio && !preset.AudioOnly:
+		preset, _ = FindFormatPreset(nil, AudioFormatPresetName)
+	case mode == types.DownloadModeVideo && preset.AudioOnly:
+		preset, _ = FindFormatPreset(nil, DefaultFormatPresetName)
+	}
+	return preset
+}
+
+// ValidateFormatPreset checks that a preset can be handed to yt-dlp
+func ValidateFormatPreset(preset types.FormatPreset) error {
+	if strings.TrimSpace(preset.Name) == "" {
+		return fmt.Errorf("format preset name is required")
+	}
+	if strings.TrimSpace(preset.Format) == "" {
+		return fmt.Errorf("format preset %q has no format selector", preset.Name)
+	}
+	if !preset.AudioOnly {
+		switch preset.Container {
+		case "", "mp4", "webm":
+		default:
+			return fmt.Errorf("format preset %q: unsupported container %q (use mp4 or webm)", preset.Name, preset.Container)
+		}
+	}
+	if preset.MaxFilesize != "" && !byteSizePattern.MatchString(preset.MaxFilesize) {
+		return fmt.Errorf("format preset %q: invalid max filesize %q (use a size such as 500M)", preset.Name, preset.MaxFilesize)
+	}
+	return nil
+}
+
+// presetContainer returns the container a video preset is merged into
+func presetContainer(preset types.FormatPreset) string {
+	if preset.Container == "" {
+		return "mp4"
+	}
+	return preset.Container
+}
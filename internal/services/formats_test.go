@@ -0,0 +1,103 @@
+package services
+
+import (
+	"maps"
+	"testing"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestDefaultPresetKeepsDownloadCheckpoints(t *testing.T) {
+	d := &Downloader{}
+	url := "https://www.youtube.com/watch?v=abc"
+
+	preset, ok := FindFormatPreset(nil, DefaultFormatPresetName)
+	if !ok {
+		t.Fatalf("built-in preset %q not found", DefaultFormatPresetName)
+	}
+	want := map[string]string{
+		"url":             url,
+		"format":          mp4FormatSelector,
+		"fallbackFormat":  webmFormatSelector,
+		"audioCodec":      "aac",
+		"audioSampleRate": audioSampleRate,
+		"audioChannels":   audioChannels,
+	}
+	if got := d.DownloadParams(url, preset); !maps.Equal(got, want) {
+		t.Fatalf("DownloadParams(%q) = %v, want %v", preset.Name, got, want)
+	}
+
+	audio, _ := FindFormatPreset(nil, AudioFormatPresetName)
+	got := d.DownloadParams(url, audio)
+	if got["mode"] != string(types.DownloadModeAudio) || got["format"] != audioFormatSelector {
+		t.Fatalf("DownloadParams(%q) = %v", audio.Name, got)
+	}
+}
+
+func TestFindFormatPreset(t *testing.T) {
+	custom := []types.FormatPreset{{Name: "best", Format: "bv*+ba/b", Container: "webm"}}
+
+	got, ok := FindFormatPreset(custom, "best")
+	if !ok || got.Container != "webm" {
+		t.Fatalf("FindFormatPreset(best) = %+v, %v; want the custom preset", got, ok)
+	}
+	if _, ok := FindFormatPreset(custom, "480p small"); !ok {
+		t.Fatal("built-in presets should stay available next to custom ones")
+	}
+	if _, ok := FindFormatPreset(custom, "8k"); ok {
+		t.Fatal("FindFormatPreset(8k) found a preset that does not exist")
+	}
+}
+
+func TestValidateFormatPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  types.FormatPreset
+		wantErr bool
+	}{
+		{"built-in", DefaultFormatPresets()[0], false},
+		{"size limit", types.FormatPreset{Name: "small", Format: "b", MaxFilesize: "1.5G"}, false},
+		{"audio ignores container", types.FormatPreset{Name: "a", Format: "ba", Container: "m4a", AudioOnly: true}, false},
+		{"missing name", types.FormatPreset{Format: "b"}, true},
+		{"missing format", types.FormatPreset{Name: "empty"}, true},
+		{"unplayable container", types.FormatPreset{Name: "mkv", Format: "b", Container: "mkv"}, true},
+		{"bad size", types.FormatPreset{Name: "small", Format: "b", MaxFilesize: "500 MB"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFormatPreset(tt.preset); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateFormatPreset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectFormatPreset(t *testing.T) {
+	custom := []types.FormatPreset{{Name: "small", Format: "b", Container: "mp4"}}
+	tests := []struct {
+		name        string
+		defaultName string
+		defaultMode types.DownloadMode
+		taskPreset  string
+		taskMode    types.DownloadMode
+		want        string
+	}{
+		{"settings default", "small", "", "", "", "small"},
+		{"unknown default", "gone", "", "", "", DefaultFormatPresetName},
+		{"task preset over settings", "small", "", "best", "", "best"},
+		{"audio mode from settings", "small", types.DownloadModeAudio, "", "", AudioFormatPresetName},
+		{"task preset over audio mode from settings", "small", types.DownloadModeAudio, "best", "", "best"},
+		{"task video mode over audio mode from settings", "small", types.DownloadModeAudio, "", types.DownloadModeVideo, "small"},
+		{"task audio mode over preset", "small", "", "best", types.DownloadModeAudio, AudioFormatPresetName},
+		{"task video mode over audio preset", "small", "", AudioFormatPresetName, types.DownloadModeVideo, DefaultFormatPresetName},
+		{"audio preset as default", AudioFormatPresetName, "", "", "", AudioFormatPresetName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectFormatPreset(custom, tt.defaultName, tt.defaultMode, tt.taskPreset, tt.taskMode)
+			if got.Name != tt.want {
+				t.Fatalf("SelectFormatPreset() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// localFileIDLength is the number of hex digits of the content hash used as
+// the video ID of an imported file
+const localFileIDLength = 16
+
+// MediaInfo describes a local media file as reported by ffprobe
+type MediaInfo struct {
+	Duration   float64 // seconds, 0 if unknown
+	FormatName string
+	HasVideo   bool
+	HasAudio   bool
+	Width      int // of the first video stream
+	Height     int
+}
+
+// ffprobeOutput is the subset of `ffprobe -of json` output MediaInfo is read
+// from
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+}
+
+// ProbeMedia reads the duration, stream layout and resolution of a local
+// media file
+func (d *Downloader) ProbeMedia(ctx context.Context, path string) (*MediaInfo, error) {
+	ffprobePath, err := d.pathFinder.FindExecutable("ffprobe")
+	if err != nil {
+		slog.Error("ffprobe not found", "error", err)
+		return nil, fmt.Errorf("%w: ffprobe not found (it is installed with ffmpeg): %v", ErrDependencyMissing, err)
+	}
+
+	cmd := commandContext(ctx, ffprobePath,
+		"-v", "error",
+		"-show_entries", "format=format_name,duration:stream=codec_type,width,height",
+		"-of", "json",
+		path,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("not a readable media file: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	info, err := parseFFprobeOutput(output)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Media probed", "path", path, "format", info.FormatName, "duration", info.Duration,
+		"video", info.HasVideo, "audio", info.HasAudio, "width", info.Width, "height", info.Height)
+	return info, nil
+}
+
+// parseFFprobeOutput reads a MediaInfo from `ffprobe -of json` output
+func parseFFprobeOutput(output []byte) (*MediaInfo, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %v", err)
+	}
+
+	info := &MediaInfo{FormatName: probe.Format.FormatName}
+	if duration, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = duration
+	}
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			if !info.HasVideo {
+				info.Width, info.Height = stream.Width, stream.Height
+			}
+			info.HasVideo = true
+		case "audio":
+			info.HasAudio = true
+		}
+	}
+	return info, nil
+}
+
+// LocalFileID derives a stable video ID for a local file from its content, so
+// importing the same recording twice is detected as a duplicate
+func LocalFileID(path string) (string, error) {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	return sum[:localFileIDLength], nil
+}
+
+// ImportFile places the file at src into taskDir as "source" plus its
+// extension and returns that name. A hard link is used when src is on the
+// same volume; otherwise the file is copied.
+func (s *Storage) ImportFile(src string, taskDir string) (string, error) {
+	name := "source" + strings.ToLower(filepath.Ext(src))
+	dst := filepath.Join(taskDir, name)
+
+	err := os.Link(src, dst)
+	if err == nil {
+		return name, nil
+	}
+	slog.Debug("Hard link failed, copying imported file", "src", src, "error", err)
+
+	if err := copyFile(src, dst); err != nil {
+		return "", fmt.Errorf("failed to copy %s into task directory: %w", filepath.Base(src), err)
+	}
+	return name, nil
+}
+
+// copyFile copies src to the new file dst, removing dst again when the copy
+// fails part way
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			slog.Warn("close imported file", "path", src, "error", err)
+		}
+	}()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(dst); removeErr != nil {
+			slog.Warn("remove partial copy", "path", dst, "error", removeErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFileID(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a, err := LocalFileID(write("a.mp4", "recording"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, _ := LocalFileID(write("renamed.mov", "recording"))
+	other, _ := LocalFileID(write("b.mp4", "another recording"))
+
+	if len(a) != localFileIDLength {
+		t.Errorf("LocalFileID() = %q, want %d hex digits", a, localFileIDLength)
+	}
+	if a != renamed {
+		t.Errorf("the same content got IDs %q and %q", a, renamed)
+	}
+	if a == other {
+		t.Errorf("different content got the same ID %q", a)
+	}
+	if _, err := LocalFileID(filepath.Join(dir, "missing.mp4")); err == nil {
+		t.Error("LocalFileID() of a missing file succeeded")
+	}
+}
+
+func TestImportFile(t *testing.T) {
+	storage := NewStorage(t.TempDir())
+	src := filepath.Join(t.TempDir(), "Talk.MP4")
+	if err := os.WriteFile(src, []byte("recording"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	taskDir := t.TempDir()
+	name, err := storage.ImportFile(src, taskDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "source.mp4" {
+		t.Errorf("ImportFile() = %q, want source.mp4", name)
+	}
+	if data, err := os.ReadFile(filepath.Join(taskDir, name)); err != nil || string(data) != "recording" {
+		t.Errorf("imported file = %q, %v", data, err)
+	}
+
+	// An existing file is neither replaced nor removed
+	if _, err := storage.ImportFile(src, taskDir); err == nil {
+		t.Error("ImportFile() replaced an existing source file")
+	}
+	if _, err := os.Stat(filepath.Join(taskDir, name)); err != nil {
+		t.Errorf("failed import removed the existing file: %v", err)
+	}
+}
+
+func TestCopyFileRemovesPartialCopy(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "copy")
+
+	// Reading a directory fails after dst has been created
+	if err := copyFile(t.TempDir(), dst); err == nil {
+		t.Fatal("copyFile() of a directory succeeded")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial copy left behind: %v", err)
+	}
+
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "data" {
+		t.Errorf("copied %q, want data", data)
+	}
+}
+
+func TestParseFFprobeOutput(t *testing.T) {
+	output := `{
+		"streams": [
+			{"codec_type": "audio"},
+			{"codec_type": "video", "width": 1920, "height": 1080},
+			{"codec_type": "video", "width": 320, "height": 180}
+		],
+		"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "212.480000"}
+	}`
+	info, err := parseFFprobeOutput([]byte(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MediaInfo{Duration: 212.48, FormatName: "mov,mp4,m4a,3gp,3g2,mj2", HasVideo: true, HasAudio: true, Width: 1920, Height: 1080}
+	if *info != want {
+		t.Errorf("parseFFprobeOutput() = %+v, want %+v", *info, want)
+	}
+
+	info, err = parseFFprobeOutput([]byte(`{"streams": [{"codec_type": "audio"}], "format": {"format_name": "wav", "duration": "N/A"}}`))
+	if err != nil || info.HasVideo || !info.HasAudio || info.Duration != 0 {
+		t.Errorf("parseFFprobeOutput(audio only) = %+v, %v", info, err)
+	}
+
+	if _, err := parseFFprobeOutput([]byte("not json")); err == nil {
+		t.Error("parseFFprobeOutput() accepted invalid output")
+	}
+}
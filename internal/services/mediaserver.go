@@ -160,8 +160,11 @@ func (m *MediaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			slog.Error("write VTT content", "error", err)
 		}
 		return
-	case ".aac", ".m4a":
+	case ".aac":
 		w.Header().Set("Content-Type", "audio/aac")
+	case ".m4a":
+		// Audio-only downloads are AAC in an MP4 container
+		w.Header().Set("Content-Type", "audio/mp4")
 	}
 
 	// Use http.ServeContent for proper range request support
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestMigrateMetadata(t *testing.T) {
+	storage := NewStorage(t.TempDir())
+	dir := filepath.Join(storage.GetWorkspace(), "task")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := `{"id": "t1", "url": "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "platform": "youtube", "videoId": "dQw4w9WgXcQ",
+		"title": "Long talk", "channel": "Gophers", "duration": "75:00", "thumbnail": "https://i.ytimg.com/vi/dQw4w9WgXcQ/hq.jpg", "status": "done"}`
+	metaPath := filepath.Join(dir, "meta.json")
+	if err := os.WriteFile(metaPath, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := storage.LoadMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.DurationSeconds != 4500 || task.Duration != "1:15:00" {
+		t.Errorf("duration migrated to %d, %q; want 4500, 1:15:00", task.DurationSeconds, task.Duration)
+	}
+	if task.Metadata == nil || task.Metadata.ID != "dQw4w9WgXcQ" || task.Metadata.Title != "Long talk" || task.Metadata.Duration != 4500 {
+		t.Errorf("metadata migrated to %+v", task.Metadata)
+	}
+	if saved, _ := os.ReadFile(metaPath); string(saved) != old {
+		t.Errorf("LoadMetadata() rewrote meta.json: %s", saved)
+	}
+
+	tm := NewTaskManager(storage)
+	if n, err := tm.MigrateMetadata(); err != nil || n != 1 {
+		t.Fatalf("MigrateMetadata() = %d, %v; want 1 task migrated", n, err)
+	}
+	saved, err := os.ReadFile(metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), `"durationSeconds": 4500`) {
+		t.Errorf("migrated meta.json was not saved: %s", saved)
+	}
+	if _, err := os.Stat(metaPath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+	if n, err := tm.MigrateMetadata(); err != nil || n != 0 {
+		t.Errorf("second MigrateMetadata() = %d, %v; want nothing to migrate", n, err)
+	}
+}
+
+func TestUpdateTaskMetadataKeepsFullMetadata(t *testing.T) {
+	tm := NewTaskManager(NewStorage(t.TempDir()))
+	task, err := tm.CreateTask("https://www.youtube.com/watch?v=dQw4w9WgXcQ", "en", types.VideoMetadata{
+		ID:       "dQw4w9WgXcQ",
+		Platform: "youtube",
+		Title:    "From the listing",
+		Duration: 61,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Duration != "01:01" || task.Metadata == nil || task.Metadata.Platform != "youtube" {
+		t.Fatalf("CreateTask() = %+v", task)
+	}
+
+	err = tm.UpdateTaskMetadata(task.ID, types.VideoMetadata{
+		ID:          "dQw4w9WgXcQ",
+		Platform:    "elsewhere",
+		Title:       "Full title",
+		Channel:     "Gophers",
+		ChannelID:   "UC123",
+		Duration:    3723,
+		PublishedAt: "2024-01-02T00:00:00Z",
+		ViewCount:   1000,
+		Description: "About the talk",
+		Tags:        []string{"go", "talk"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err = tm.GetTask(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != "Full title" || task.Duration != "1:02:03" || task.DurationSeconds != 3723 {
+		t.Errorf("task fields not updated: %q, %q, %d", task.Title, task.Duration, task.DurationSeconds)
+	}
+	meta := task.Metadata
+	if meta == nil || meta.Platform != "youtube" || meta.ChannelID != "UC123" || meta.Description != "About the talk" || len(meta.Tags) != 2 {
+		t.Errorf("Metadata = %+v", meta)
+	}
+
+	// A live stream reports no duration; the known one is kept
+	if err := tm.UpdateTaskMetadata(task.ID, types.VideoMetadata{Title: "Full title"}); err != nil {
+		t.Fatal(err)
+	}
+	task, _ = tm.GetTask(task.ID)
+	if task.DurationSeconds != 3723 || task.Metadata.Duration != 3723 {
+		t.Errorf("duration dropped: %d, %d", task.DurationSeconds, task.Metadata.Duration)
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"transcube-webapp/internal/types"
+)
+
+// proxySchemes are the proxy protocols both yt-dlp and net/http support
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ValidateNetworkSettings checks that the network settings can be handed to
+// yt-dlp and the HTTP clients
+func ValidateNetworkSettings(network types.NetworkSettings) error {
+	if _, err := parseProxy(network.Proxy); err != nil {
+		return err
+	}
+	if network.RateLimit != "" && !byteSizePattern.MatchString(network.RateLimit) {
+		return fmt.Errorf("invalid rate limit %q (use a speed such as 2M)", network.RateLimit)
+	}
+	if network.Retries < 0 {
+		return fmt.Errorf("retries cannot be negative")
+	}
+	if network.SleepRequests < 0 || network.SleepInterval < 0 {
+		return fmt.Errorf("sleep intervals cannot be negative")
+	}
+	return nil
+}
+
+// parseProxy parses a proxy URL, returning nil for an empty one
+func parseProxy(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q (use e.g. http://host:8080 or socks5://host:1080)", redactProxy(proxy))
+	}
+	if !slices.Contains(proxySchemes, u.Scheme) {
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", u.Scheme)
+	}
+	return u, nil
+}
+
+// redactProxy masks the user name and password of a proxy URL, which may not
+// parse, so it can be shown in errors and logs
+func redactProxy(proxy string) string {
+	scheme, rest, found := strings.Cut(proxy, "://")
+	if !found {
+		scheme, rest = "", proxy
+	}
+	host, path := rest, ""
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		host, path = rest[:i], rest[i:]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = "xxxxx@" + host[i+1:]
+	}
+	if found {
+		return scheme + "://" + host + path
+	}
+	return host + path
+}
+
+// SetNetwork replaces the proxy, rate limit and retry options passed to
+// yt-dlp
+func (d *Downloader) SetNetwork(network types.NetworkSettings) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.network = network
+}
+
+// ytDlpArgs returns the options every yt-dlp invocation for url starts with:
+// the network settings and the cookies of its platform
+func (d *Downloader) ytDlpArgs(url string) []string {
+	d.mu.RLock()
+	network := d.network
+	d.mu.RUnlock()
+
+	var args []string
+	if network.Proxy != "" {
+		args = append(args, "--proxy", network.Proxy)
+	}
+	if network.RateLimit != "" {
+		args = append(args, "--limit-rate", network.RateLimit)
+	}
+	if network.Retries > 0 {
+		retries := strconv.Itoa(network.Retries)
+		args = append(args, "--retries", retries, "--fragment-retries", retries)
+	}
+	if network.SleepRequests > 0 {
+		args = append(args, "--sleep-requests", strconv.FormatFloat(network.SleepRequests, 'f', -1, 64))
+	}
+	if network.SleepInterval > 0 {
+		args = append(args, "--sleep-interval", strconv.FormatFloat(network.SleepInterval, 'f', -1, 64))
+	}
+	return append(args, d.authArgs(url)...)
+}
+
+// newHTTPTransport returns a transport that connects through proxy, or
+// through the proxy set in the environment when proxy is empty
+func newHTTPTransport(proxy string) (*http.Transport, error) {
+	u, err := parseProxy(proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if u != nil {
+		transport.Proxy = http.ProxyURL(u)
+	}
+	return transport, nil
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// processWaitDelay bounds how long Wait blocks on a killed process whose
+// output pipes are still held open by its descendants.
+const processWaitDelay = 5 * time.Second
+
+// commandContext builds an exec.Cmd bound to ctx. The child is started in its
+// own process group and the whole group is killed when ctx is cancelled, so
+// helpers spawned by the tool (e.g. ffmpeg launched by yt-dlp for merging) do
+// not outlive a cancelled task.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	configureProcessGroup(cmd)
+	cmd.WaitDelay = processWaitDelay
+	return cmd
+}
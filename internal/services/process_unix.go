@@ -0,0 +1,17 @@
+//go:build !windows
+
+package services
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessGroup places the command in a new process group and makes
+// cancellation kill the entire group rather than only the direct child.
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
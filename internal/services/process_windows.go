@@ -0,0 +1,9 @@
+//go:build windows
+
+package services
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on Windows, where exec.CommandContext's
+// default cancellation already terminates the direct child.
+func configureProcessGroup(cmd *exec.Cmd) {}
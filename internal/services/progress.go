@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressUpdate reports how far a running tool has got. Fraction is in
+// [0, 1]; the transfer fields are zero when the tool does not report them.
+type ProgressUpdate struct {
+	Fraction        float64
+	DownloadedBytes int64
+	TotalBytes      int64
+	Speed           float64 // bytes per second
+	ETA             time.Duration
+}
+
+// ProgressFunc receives progress updates while a tool runs. It is called from
+// the goroutine copying the tool's output and must not block.
+type ProgressFunc func(ProgressUpdate)
+
+// ytDlpProgressPrefix marks the machine-readable lines requested with
+// ytDlpProgressTemplate
+const ytDlpProgressPrefix = "[transcube-progress]"
+
+// ytDlpProgressTemplate makes yt-dlp print one line per progress tick with
+// raw byte counts instead of its human-formatted status line. Missing values
+// are printed as NA.
+const ytDlpProgressTemplate = "download:" + ytDlpProgressPrefix +
+	" %(progress.downloaded_bytes)s %(progress.total_bytes)s %(progress.total_bytes_estimate)s %(progress.speed)s %(progress.eta)s"
+
+// parseYtDlpProgress parses a line printed with ytDlpProgressTemplate
+func parseYtDlpProgress(line string) (ProgressUpdate, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), ytDlpProgressPrefix)
+	if !ok {
+		return ProgressUpdate{}, false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) != 5 {
+		return ProgressUpdate{}, false
+	}
+
+	var update ProgressUpdate
+	update.DownloadedBytes = int64(parseNumber(fields[0]))
+	update.TotalBytes = int64(parseNumber(fields[1]))
+	if update.TotalBytes == 0 {
+		update.TotalBytes = int64(parseNumber(fields[2]))
+	}
+	update.Speed = parseNumber(fields[3])
+	update.ETA = time.Duration(parseNumber(fields[4]) * float64(time.Second))
+	if update.TotalBytes > 0 {
+		update.Fraction = clampFraction(float64(update.DownloadedBytes) / float64(update.TotalBytes))
+	}
+	return update, true
+}
+
+// ffmpegDurationPattern matches the input duration ffmpeg logs on stderr
+var ffmpegDurationPattern = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+
+// ffmpegProgress tracks the key=value blocks ffmpeg writes with
+// -progress pipe:1. The total duration is taken from ffmpeg's own input
+// summary so callers do not need to probe the file first.
+type ffmpegProgress struct {
+	total time.Duration
+}
+
+// parseLine consumes one line of ffmpeg output. It reports whether the line
+// belonged to the progress stream and, when it carried a position, the update.
+func (p *ffmpegProgress) parseLine(line string) (update ProgressUpdate, consumed, ok bool) {
+	line = strings.TrimSpace(line)
+	if match := ffmpegDurationPattern.FindStringSubmatch(line); match != nil {
+		hours, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		seconds, _ := strconv.ParseFloat(match[3], 64)
+		p.total = time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+			time.Duration(seconds*float64(time.Second))
+		return ProgressUpdate{}, false, false
+	}
+
+	key, value, found := strings.Cut(line, "=")
+	if !found || strings.ContainsAny(key, " \t") {
+		return ProgressUpdate{}, false, false
+	}
+	switch key {
+	case "out_time_us", "out_time_ms": // both are in microseconds
+		if p.total <= 0 {
+			return ProgressUpdate{}, true, false
+		}
+		micros, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return ProgressUpdate{}, true, false
+		}
+		position := time.Duration(micros) * time.Microsecond
+		return ProgressUpdate{Fraction: clampFraction(float64(position) / float64(p.total))}, true, true
+	case "progress":
+		if value == "end" {
+			return ProgressUpdate{Fraction: 1}, true, true
+		}
+		return ProgressUpdate{}, true, false
+	case "frame", "fps", "bitrate", "total_size", "out_time", "dup_frames", "drop_frames", "speed":
+		return ProgressUpdate{}, true, false
+	default:
+		if strings.HasPrefix(key, "stream_") {
+			return ProgressUpdate{}, true, false
+		}
+		return ProgressUpdate{}, false, false
+	}
+}
+
+// yapProgressPattern matches the progress lines yap prints while
+// transcribing: a percentage such as "42%" or "42.5 %" on its own, optionally
+// after a spinner, a "Transcribing…" label or a progress bar. Other lines that
+// mention a percentage are left in the output, where they may explain a
+// failure.
+var yapProgressPattern = regexp.MustCompile(`^(?:\p{Braille}\s*)?(?:Transcribing[^\d%]*)?(?:\[[^\]\d]*\]\s*|[█▉▊▋▌▍▎▏░▒▓]+\s*)?(\d{1,3}(?:\.\d+)?)\s?%$`)
+
+// parseYapProgress parses a yap progress line
+func parseYapProgress(line string) (ProgressUpdate, bool) {
+	match := yapProgressPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return ProgressUpdate{}, false
+	}
+	percent, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || percent > 100 {
+		return ProgressUpdate{}, false
+	}
+	return ProgressUpdate{Fraction: percent / 100}, true
+}
+
+// lineWriter splits a command's output into lines as it is written. Lines for
+// which onLine returns true are treated as progress noise and left out of the
+// captured output, which is kept for logs and error messages.
+type lineWriter struct {
+	mu      sync.Mutex
+	onLine  func(line string) bool
+	partial []byte
+	output  bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexAny(w.partial, "\r\n")
+		if idx < 0 {
+			break
+		}
+		w.handleLocked(string(w.partial[:idx]))
+		w.partial = w.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) handleLocked(line string) {
+	if line == "" {
+		return
+	}
+	if w.onLine != nil && w.onLine(line) {
+		return
+	}
+	w.output.WriteString(line)
+	w.output.WriteByte('\n')
+}
+
+// Bytes returns the captured output, including any unterminated final line
+func (w *lineWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.handleLocked(string(w.partial))
+		w.partial = nil
+	}
+	return append([]byte(nil), w.output.Bytes()...)
+}
+
+// runWithLines runs cmd with stdout and stderr combined, passing each output
+// line to onLine as it arrives. It returns the captured output like
+// CombinedOutput, minus the lines onLine consumed.
+func runWithLines(cmd *exec.Cmd, onLine func(line string) bool) ([]byte, error) {
+	w := &lineWriter{onLine: onLine}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	return w.Bytes(), err
+}
+
+func parseNumber(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+func clampFraction(f float64) float64 {
+	switch {
+	case f < 0:
+		return 0
+	case f > 1:
+		return 1
+	default:
+		return f
+	}
+}
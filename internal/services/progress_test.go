@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseYtDlpProgress(t *testing.T) {
+	tests := []struct {
+		line string
+		want ProgressUpdate
+		ok   bool
+	}{
+		{
+			line: "[transcube-progress] 1048576 4194304 NA 524288.5 6",
+			want: ProgressUpdate{Fraction: 0.25, DownloadedBytes: 1048576, TotalBytes: 4194304, Speed: 524288.5, ETA: 6 * time.Second},
+			ok:   true,
+		},
+		{
+			line: "  [transcube-progress] 500 NA 1000 NA NA",
+			want: ProgressUpdate{Fraction: 0.5, DownloadedBytes: 500, TotalBytes: 1000},
+			ok:   true,
+		},
+		{
+			line: "[transcube-progress] 500 NA NA NA NA",
+			want: ProgressUpdate{DownloadedBytes: 500},
+			ok:   true,
+		},
+		{line: "[transcube-progress] 500 1000", ok: false},
+		{line: "[download]  25.0% of 4.00MiB at 512.00KiB/s ETA 00:06", ok: false},
+		{line: "ERROR: [youtube] abc: Video unavailable", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseYtDlpProgress(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseYtDlpProgress(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFFmpegProgress(t *testing.T) {
+	p := &ffmpegProgress{}
+	steps := []struct {
+		line     string
+		consumed bool
+		ok       bool
+		fraction float64
+	}{
+		// Before the duration is known positions cannot be mapped
+		{"out_time_us=1000000", true, false, 0},
+		{"  Duration: 00:01:40.00, start: 0.000000, bitrate: 128 kb/s", false, false, 0},
+		{"Stream #0:0: Audio: aac, 44100 Hz, stereo", false, false, 0},
+		{"out_time_us=25000000", true, true, 0.25},
+		{"out_time_ms=50000000", true, true, 0.5},
+		{"out_time=00:00:50.000000", true, false, 0},
+		{"stream_0_0_q=-1.0", true, false, 0},
+		{"bitrate= 128.0kbits/s", true, false, 0},
+		{"progress=continue", true, false, 0},
+		{"out_time_us=N/A", true, false, 0},
+		{"out_time_us=200000000", true, true, 1},
+		{"progress=end", true, true, 1},
+		{"Error while decoding stream #0:0: Invalid data found", false, false, 0},
+	}
+	for _, step := range steps {
+		update, consumed, ok := p.parseLine(step.line)
+		if consumed != step.consumed || ok != step.ok || update.Fraction != step.fraction {
+			t.Errorf("parseLine(%q) = %+v, %v, %v; want fraction %v, %v, %v", step.line, update, consumed, ok, step.fraction, step.consumed, step.ok)
+		}
+	}
+}
+
+func TestParseYapProgress(t *testing.T) {
+	tests := []struct {
+		line     string
+		fraction float64
+		ok       bool
+	}{
+		{"42%", 0.42, true},
+		{"  42.5 %", 0.425, true},
+		{"100%", 1, true},
+		{"⠋ Transcribing… 7%", 0.07, true},
+		{"Transcribing audio... 60%", 0.6, true},
+		{"[██████    ] 60%", 0.6, true},
+		{"█████ 50%", 0.5, true},
+		{"150%", 0, false},
+		{"Error: disk 100% full", 0, false},
+		{"Error: disk usage at 100%", 0, false},
+		{"Loaded 3 of 10 assets (30%)", 0, false},
+		{"1 of 2 done 50%", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseYapProgress(tt.line)
+		if ok != tt.ok || got.Fraction != tt.fraction {
+			t.Errorf("parseYapProgress(%q) = %v, %v; want %v, %v", tt.line, got.Fraction, ok, tt.fraction, tt.ok)
+		}
+	}
+}
+
+func TestLineWriterKeepsUnconsumedLines(t *testing.T) {
+	var fractions []float64
+	w := &lineWriter{onLine: func(line string) bool {
+		update, ok := parseYapProgress(line)
+		if ok {
+			fractions = append(fractions, update.Fraction)
+		}
+		return ok
+	}}
+	for _, chunk := range []string{"10%\r20", "%\rError: disk 100% full\n", "exit"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := string(w.Bytes()); got != "Error: disk 100% full\nexit\n" {
+		t.Errorf("captured output = %q", got)
+	}
+	if len(fractions) != 2 || fractions[0] != 0.1 || fractions[1] != 0.2 {
+		t.Errorf("progress = %v, want [0.1 0.2]", fractions)
+	}
+}
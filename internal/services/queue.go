@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"transcube-webapp/internal/types"
+)
+
+// Default number of tasks allowed to run each stage at the same time
+const (
+	DefaultDownloadWorkers      = 2
+	DefaultTranscriptionWorkers = 1
+	DefaultSummaryWorkers       = 2
+)
+
+// TaskQueue orders tasks waiting to be processed and limits how many tasks
+// may run each pipeline stage concurrently. A single FIFO order is shared by
+// all stages: when a stage slot frees up, the waiting task that sits earliest
+// in the order gets it. The order is persisted so it survives a restart.
+type TaskQueue struct {
+	mu      sync.Mutex
+	order   []string
+	limits  map[types.TaskStatus]int
+	active  map[types.TaskStatus]int
+	waiting map[types.TaskStatus]map[string]bool
+	changed chan struct{}
+	storage *Storage
+}
+
+var defaultStageWorkers = map[types.TaskStatus]int{
+	types.TaskStatusDownloading:  DefaultDownloadWorkers,
+	types.TaskStatusTranscribing: DefaultTranscriptionWorkers,
+	types.TaskStatusSummarizing:  DefaultSummaryWorkers,
+}
+
+func NewTaskQueue(storage *Storage) *TaskQueue {
+	limits := make(map[types.TaskStatus]int, len(defaultStageWorkers))
+	for stage, workers := range defaultStageWorkers {
+		limits[stage] = workers
+	}
+	return &TaskQueue{
+		limits:  limits,
+		active:  make(map[types.TaskStatus]int),
+		waiting: make(map[types.TaskStatus]map[string]bool),
+		changed: make(chan struct{}),
+		storage: storage,
+	}
+}
+
+// SetLimit changes the number of concurrent workers for a stage. Values below
+// one restore the stage default.
+func (q *TaskQueue) SetLimit(stage types.TaskStatus, workers int) {
+	if workers < 1 {
+		workers = defaultStageWorkers[stage]
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.limits[stage] = workers
+	q.broadcastLocked()
+}
+
+// Load replaces the in-memory order with the persisted one
+func (q *TaskQueue) Load() ([]string, error) {
+	ids, err := q.storage.LoadQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.order = append([]string(nil), ids...)
+	q.broadcastLocked()
+	return append([]string(nil), q.order...), nil
+}
+
+// Add appends the task to the end of the queue if it is not already queued.
+// The queue is left unchanged when it cannot be persisted.
+func (q *TaskQueue) Add(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.indexLocked(taskID) >= 0 {
+		return nil
+	}
+	q.order = append(q.order, taskID)
+	if err := q.persistLocked(); err != nil {
+		q.order = q.order[:len(q.order)-1]
+		return err
+	}
+	q.broadcastLocked()
+	return nil
+}
+
+// Remove drops the task from the queue
+func (q *TaskQueue) Remove(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	idx := q.indexLocked(taskID)
+	if idx < 0 {
+		return nil
+	}
+	q.order = append(q.order[:idx], q.order[idx+1:]...)
+	q.broadcastLocked()
+	return q.persistLocked()
+}
+
+// Move places the task at the given zero-based position, clamping positions
+// outside the queue to its ends.
+func (q *TaskQueue) Move(taskID string, position int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	idx := q.indexLocked(taskID)
+	if idx < 0 {
+		return fmt.Errorf("task %s is not queued", taskID)
+	}
+
+	q.order = append(q.order[:idx], q.order[idx+1:]...)
+	if position < 0 {
+		position = 0
+	}
+	if position > len(q.order) {
+		position = len(q.order)
+	}
+	q.order = append(q.order[:position], append([]string{taskID}, q.order[position:]...)...)
+	q.broadcastLocked()
+	return q.persistLocked()
+}
+
+// IDs returns the queued task IDs in order
+func (q *TaskQueue) IDs() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]string(nil), q.order...)
+}
+
+// TryAcquire takes a slot for the stage if one is free and no task ahead of
+// this one is waiting for it. The returned release function must be called
+// when the stage finishes.
+func (q *TaskQueue) TryAcquire(stage types.TaskStatus, taskID string) (func(), bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.indexLocked(taskID) < 0 {
+		q.order = append(q.order, taskID)
+		if err := q.persistLocked(); err != nil {
+			slog.Warn("persist task queue", "error", err)
+		}
+	}
+
+	return q.tryAcquireLocked(stage, taskID)
+}
+
+// Acquire blocks until the task may run the stage or ctx is done.
+func (q *TaskQueue) Acquire(ctx context.Context, stage types.TaskStatus, taskID string) (func(), error) {
+	q.mu.Lock()
+	if q.waiting[stage] == nil {
+		q.waiting[stage] = make(map[string]bool)
+	}
+	q.waiting[stage][taskID] = true
+
+	for {
+		if release, ok := q.tryAcquireLocked(stage, taskID); ok {
+			q.mu.Unlock()
+			return release, nil
+		}
+
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			delete(q.waiting[stage], taskID)
+			q.broadcastLocked()
+			q.mu.Unlock()
+			return nil, ctx.Err()
+		case <-changed:
+		}
+
+		q.mu.Lock()
+	}
+}
+
+func (q *TaskQueue) tryAcquireLocked(stage types.TaskStatus, taskID string) (func(), bool) {
+	limit := q.limits[stage]
+	if limit < 1 {
+		limit = 1
+	}
+	if q.active[stage] >= limit {
+		return nil, false
+	}
+	if q.nextWaitingLocked(stage, taskID) != taskID {
+		return nil, false
+	}
+
+	delete(q.waiting[stage], taskID)
+	q.active[stage]++
+	q.broadcastLocked()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.active[stage]--
+			q.broadcastLocked()
+		})
+	}, true
+}
+
+// nextWaitingLocked returns the earliest queued task that is either waiting
+// for the stage or is the candidate asking for it.
+func (q *TaskQueue) nextWaitingLocked(stage types.TaskStatus, candidate string) string {
+	waiting := q.waiting[stage]
+	for _, id := range q.order {
+		if id == candidate || waiting[id] {
+			return id
+		}
+	}
+	return candidate
+}
+
+func (q *TaskQueue) indexLocked(taskID string) int {
+	for i, id := range q.order {
+		if id == taskID {
+			return i
+		}
+	}
+	return -1
+}
+
+// broadcastLocked wakes every goroutine blocked in Acquire so it can
+// re-evaluate whether it may proceed.
+func (q *TaskQueue) broadcastLocked() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+func (q *TaskQueue) persistLocked() error {
+	return q.storage.SaveQueue(q.order)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+func newTestQueue(t *testing.T, ids ...string) *TaskQueue {
+	t.Helper()
+	q := NewTaskQueue(NewStorage(t.TempDir()))
+	for _, id := range ids {
+		if err := q.Add(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return q
+}
+
+// waitUntilWaiting blocks until the task is parked in Acquire for the stage
+func waitUntilWaiting(t *testing.T, q *TaskQueue, stage types.TaskStatus, taskID string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		q.mu.Lock()
+		waiting := q.waiting[stage][taskID]
+		q.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%s never started waiting for %s", taskID, stage)
+}
+
+type acquired struct {
+	taskID  string
+	release func()
+	err     error
+}
+
+func acquireAsync(ctx context.Context, q *TaskQueue, stage types.TaskStatus, taskID string, done chan<- acquired) {
+	go func() {
+		release, err := q.Acquire(ctx, stage, taskID)
+		done <- acquired{taskID, release, err}
+	}()
+}
+
+func expectAcquired(t *testing.T, done <-chan acquired, taskID string) acquired {
+	t.Helper()
+	select {
+	case got := <-done:
+		if got.err != nil || got.taskID != taskID {
+			t.Fatalf("Acquire returned %s (err %v), want %s", got.taskID, got.err, taskID)
+		}
+		return got
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s never acquired the stage", taskID)
+		return acquired{}
+	}
+}
+
+func expectBlocked(t *testing.T, done <-chan acquired) {
+	t.Helper()
+	select {
+	case got := <-done:
+		t.Fatalf("%s acquired the stage while it should wait", got.taskID)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestTaskQueueStageLimits(t *testing.T) {
+	q := newTestQueue(t, "a", "b", "c")
+	stage := types.TaskStatusTranscribing
+
+	releaseA, ok := q.TryAcquire(stage, "a")
+	if !ok {
+		t.Fatal("first task could not take a free transcription slot")
+	}
+	if _, ok := q.TryAcquire(stage, "b"); ok {
+		t.Fatalf("second task ran past the limit of %d transcription worker", DefaultTranscriptionWorkers)
+	}
+	if _, ok := q.TryAcquire(types.TaskStatusDownloading, "b"); !ok {
+		t.Fatal("a busy transcription stage blocked downloads")
+	}
+
+	q.SetLimit(stage, 2)
+	releaseB, ok := q.TryAcquire(stage, "b")
+	if !ok {
+		t.Fatal("raised limit did not free a slot")
+	}
+	if _, ok := q.TryAcquire(stage, "c"); ok {
+		t.Fatal("third task ran past the raised limit of 2")
+	}
+
+	releaseA()
+	releaseA()
+	releaseC, ok := q.TryAcquire(stage, "c")
+	if !ok {
+		t.Fatal("released slot was not handed out")
+	}
+	if _, ok := q.TryAcquire(stage, "d"); ok {
+		t.Fatal("releasing twice freed two slots")
+	}
+	releaseB()
+	releaseC()
+}
+
+func TestTaskQueueHandsSlotsOutInOrder(t *testing.T) {
+	q := newTestQueue(t, "a", "b", "c")
+	stage := types.TaskStatusTranscribing
+
+	releaseA, ok := q.TryAcquire(stage, "a")
+	if !ok {
+		t.Fatal("first task could not take a free slot")
+	}
+
+	// c asks first but b sits ahead of it in the queue
+	doneC := make(chan acquired, 1)
+	acquireAsync(context.Background(), q, stage, "c", doneC)
+	waitUntilWaiting(t, q, stage, "c")
+	doneB := make(chan acquired, 1)
+	acquireAsync(context.Background(), q, stage, "b", doneB)
+	waitUntilWaiting(t, q, stage, "b")
+
+	releaseA()
+	b := expectAcquired(t, doneB, "b")
+	expectBlocked(t, doneC)
+
+	b.release()
+	c := expectAcquired(t, doneC, "c")
+	c.release()
+}
+
+func TestTaskQueueCancelledAcquireWakesNextWaiter(t *testing.T) {
+	q := newTestQueue(t, "a", "b", "c")
+	stage := types.TaskStatusTranscribing
+
+	releaseA, ok := q.TryAcquire(stage, "a")
+	if !ok {
+		t.Fatal("first task could not take a free slot")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	doneB := make(chan acquired, 1)
+	acquireAsync(ctx, q, stage, "b", doneB)
+	waitUntilWaiting(t, q, stage, "b")
+	doneC := make(chan acquired, 1)
+	acquireAsync(context.Background(), q, stage, "c", doneC)
+	waitUntilWaiting(t, q, stage, "c")
+
+	cancel()
+	select {
+	case got := <-doneB:
+		if !errors.Is(got.err, context.Canceled) {
+			t.Fatalf("cancelled Acquire error = %v, want context.Canceled", got.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelled Acquire never returned")
+	}
+
+	releaseA()
+	c := expectAcquired(t, doneC, "c")
+	c.release()
+}
+
+func TestTaskQueueMove(t *testing.T) {
+	storage := NewStorage(t.TempDir())
+	q := NewTaskQueue(storage)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := q.Add(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		taskID   string
+		position int
+		want     []string
+	}{
+		{"c", 0, []string{"c", "a", "b"}},
+		{"c", 1, []string{"a", "c", "b"}},
+		{"a", 99, []string{"c", "b", "a"}},
+		{"a", -5, []string{"a", "c", "b"}},
+	}
+	for _, step := range steps {
+		if err := q.Move(step.taskID, step.position); err != nil {
+			t.Fatalf("Move(%s, %d) error = %v", step.taskID, step.position, err)
+		}
+		if got := q.IDs(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("after Move(%s, %d) order = %v, want %v", step.taskID, step.position, got, step.want)
+		}
+	}
+
+	if saved, err := storage.LoadQueue(); err != nil || !reflect.DeepEqual(saved, []string{"a", "c", "b"}) {
+		t.Errorf("persisted order = %v, %v; want [a c b]", saved, err)
+	}
+	if err := q.Move("missing", 0); err == nil {
+		t.Error("Move() of a task that is not queued succeeded")
+	}
+}
@@ -0,0 +1,72 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResolveURLFollowsBilibiliShortLinks(t *testing.T) {
+	listing, argsLog := fakeYtDlp(t)
+	d := NewDownloader(nil)
+
+	if err := os.WriteFile(listing, []byte("WARNING: [BiliBili] falling back\nhttps://www.bilibili.com/video/BV1xx411c7mD/?p=3&spm_id_from=333.1007\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.ResolveURL(context.Background(), "https://b23.tv/AbCdEf1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://www.bilibili.com/video/BV1xx411c7mD?p=3"; got != want {
+		t.Errorf("ResolveURL(short link) = %q, want %q", got, want)
+	}
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "--print webpage_url https://b23.tv/AbCdEf1") {
+		t.Errorf("yt-dlp was not asked for the page URL: %s", args)
+	}
+
+	// Full links are canonicalized without running yt-dlp
+	if err := os.Remove(argsLog); err != nil {
+		t.Fatal(err)
+	}
+	got, err = d.ResolveURL(context.Background(), "https://m.bilibili.com/video/av170001?p=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://www.bilibili.com/video/BV17x411w7KC"; got != want {
+		t.Errorf("ResolveURL(av link) = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(argsLog); !os.IsNotExist(err) {
+		t.Error("yt-dlp ran for a link that needs no resolution")
+	}
+
+	if err := os.WriteFile(listing, []byte("FAIL"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ResolveURL(context.Background(), "https://b23.tv/AbCdEf1"); err == nil {
+		t.Error("ResolveURL() succeeded although yt-dlp failed")
+	}
+}
+
+func TestGetVideoInfoIdentifiesBilibiliParts(t *testing.T) {
+	listing, _ := fakeYtDlp(t)
+	d := NewDownloader(nil)
+
+	info := `{"id": "BV1xx411c7mD", "title": "Part 2", "uploader": "up", "duration": 90, "extractor_key": "BiliBili"}`
+	if err := os.WriteFile(listing, []byte(info), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.GetVideoInfo(context.Background(), "https://www.bilibili.com/video/BV1xx411c7mD?p=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "BV1xx411c7mD_p2" {
+		t.Errorf("GetVideoInfo(part 2).ID = %q, want BV1xx411c7mD_p2", got.ID)
+	}
+}
@@ -47,21 +47,55 @@ func (s *SettingsStore) Load() (*types.Settings, error) {
 }
 
 func (s *SettingsStore) Save(st types.Settings) error {
-	tmp := s.filePath + ".tmp"
+	return writeJSONFile(s.filePath, st, "settings")
+}
+
+// subscriptionsPath is where subscriptions are kept, next to settings.json
+func (s *SettingsStore) subscriptionsPath() string {
+	return filepath.Join(filepath.Dir(s.filePath), "subscriptions.json")
+}
+
+// LoadSubscriptions returns the saved channel subscriptions, or nil if none
+// were saved yet
+func (s *SettingsStore) LoadSubscriptions() ([]types.Subscription, error) {
+	data, err := os.ReadFile(s.subscriptionsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read subscriptions: %w", err)
+	}
+	var subs []types.Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return nil, fmt.Errorf("decode subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+func (s *SettingsStore) SaveSubscriptions(subs []types.Subscription) error {
+	if subs == nil {
+		subs = []types.Subscription{}
+	}
+	return writeJSONFile(s.subscriptionsPath(), subs, "subscriptions")
+}
+
+// writeJSONFile atomically replaces path with the indented JSON encoding of v
+func writeJSONFile(path string, v any, what string) error {
+	tmp := path + ".tmp"
 	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
 	if err != nil {
-		return fmt.Errorf("open temp settings: %w", err)
+		return fmt.Errorf("open temp %s: %w", what, err)
 	}
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "  ")
-	if err := enc.Encode(st); err != nil {
+	if err := enc.Encode(v); err != nil {
 		if closeErr := f.Close(); closeErr != nil {
-			slog.Error("close temp settings file", "error", closeErr)
+			slog.Error("close temp "+what+" file", "error", closeErr)
 		}
-		return fmt.Errorf("encode settings: %w", err)
+		return fmt.Errorf("encode %s: %w", what, err)
 	}
 	if err := f.Close(); err != nil {
-		return fmt.Errorf("close temp settings: %w", err)
+		return fmt.Errorf("close temp %s: %w", what, err)
 	}
-	return os.Rename(tmp, s.filePath)
+	return os.Rename(tmp, path)
 }
@@ -14,6 +14,7 @@ import (
 
 type Storage struct {
 	workspace string
+	onLog     func(taskDir, logType, line string)
 }
 
 func NewStorage(workspace string) *Storage {
@@ -23,6 +24,11 @@ func NewStorage(workspace string) *Storage {
 	return &Storage{workspace: workspace}
 }
 
+// SetLogHook registers fn to be called for every line appended to a task log
+func (s *Storage) SetLogHook(fn func(taskDir, logType, line string)) {
+	s.onLog = fn
+}
+
 // EnsureWorkspace creates the workspace directory if it doesn't exist
 func (s *Storage) EnsureWorkspace() error {
 	return os.MkdirAll(s.workspace, 0755)
@@ -84,27 +90,89 @@ func (s *Storage) sanitizeTitle(title string) string {
 	return sanitized
 }
 
-// SaveMetadata saves task metadata to meta.json
+// SaveMetadata atomically saves task metadata to meta.json
 func (s *Storage) SaveMetadata(task *types.Task) error {
-	metaPath := filepath.Join(task.WorkDir, "meta.json")
-	data, err := json.MarshalIndent(task, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(metaPath, data, 0644)
+	return writeJSONFile(filepath.Join(task.WorkDir, "meta.json"), task, "task metadata")
 }
 
-// LoadMetadata loads task metadata from meta.json
+// LoadMetadata loads task metadata from meta.json. Tasks written by older
+// versions are brought up to date in memory; MigrateMetadata saves them.
 func (s *Storage) LoadMetadata(taskDir string) (*types.Task, error) {
-	metaPath := filepath.Join(taskDir, "meta.json")
-	data, err := os.ReadFile(metaPath)
+	task, _, err := readMetadata(taskDir)
+	return task, err
+}
+
+// readMetadata loads and migrates meta.json, reporting whether the migration
+// changed the task
+func readMetadata(taskDir string) (*types.Task, bool, error) {
+	data, err := os.ReadFile(filepath.Join(taskDir, "meta.json"))
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	var task types.Task
-	err = json.Unmarshal(data, &task)
-	return &task, err
+	if err := json.Unmarshal(data, &task); err != nil {
+		return &task, false, err
+	}
+	return &task, migrateMetadata(&task), nil
+}
+
+// MigrateMetadata saves every meta.json in the workspace written by an older
+// version in the current format and returns how many were rewritten. Callers
+// must keep other writers of task metadata out while it runs.
+func (s *Storage) MigrateMetadata() (int, error) {
+	entries, err := os.ReadDir(s.workspace)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		taskDir := filepath.Join(s.workspace, entry.Name())
+		task, changed, err := readMetadata(taskDir)
+		if err != nil || !changed {
+			continue
+		}
+		if err := writeJSONFile(filepath.Join(taskDir, "meta.json"), task, "task metadata"); err != nil {
+			slog.Warn("Failed to save migrated task metadata", "taskDir", taskDir, "error", err)
+			continue
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// migrateMetadata brings a task read from an older meta.json up to date: it
+// derives DurationSeconds from the duration string, which is rewritten as
+// h:mm:ss past an hour, and Metadata from the task's own fields. It reports
+// whether the task changed.
+func migrateMetadata(task *types.Task) bool {
+	changed := false
+	if task.DurationSeconds == 0 && task.Duration != "" {
+		if seconds, ok := types.ParseDuration(task.Duration); ok && seconds > 0 {
+			task.DurationSeconds = seconds
+			task.Duration = types.FormatDuration(seconds)
+			changed = true
+		}
+	}
+	if task.Metadata == nil && task.Platform != "" {
+		task.Metadata = &types.VideoMetadata{
+			ID:        task.VideoID,
+			Platform:  task.Platform,
+			Title:     task.Title,
+			Channel:   task.Channel,
+			Duration:  task.DurationSeconds,
+			Thumbnail: task.Thumbnail,
+		}
+		changed = true
+	}
+	return changed
 }
 
 // GetAllTasks returns all tasks from the workspace
@@ -134,6 +202,35 @@ func (s *Storage) GetAllTasks() ([]*types.Task, error) {
 	return tasks, nil
 }
 
+// SaveQueue persists the ordered list of queued task IDs to the workspace
+func (s *Storage) SaveQueue(taskIDs []string) error {
+	if err := s.EnsureWorkspace(); err != nil {
+		return err
+	}
+	if taskIDs == nil {
+		taskIDs = []string{}
+	}
+	return writeJSONFile(filepath.Join(s.workspace, "queue.json"), taskIDs, "task queue")
+}
+
+// LoadQueue loads the persisted queue order, returning an empty list when no
+// queue has been saved yet
+func (s *Storage) LoadQueue() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(s.workspace, "queue.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	var taskIDs []string
+	if err := json.Unmarshal(data, &taskIDs); err != nil {
+		return nil, fmt.Errorf("failed to parse queue: %w", err)
+	}
+	return taskIDs, nil
+}
+
 // SaveLog saves log content to a specific log file
 func (s *Storage) SaveLog(taskDir string, logType string, content string) error {
 	logDir := filepath.Join(taskDir, "logs")
@@ -155,8 +252,43 @@ func (s *Storage) SaveLog(taskDir string, logType string, content string) error
 		}
 	}()
 
-	_, err = f.WriteString(logEntry)
-	return err
+	if _, err := f.WriteString(logEntry); err != nil {
+		return err
+	}
+
+	if s.onLog != nil {
+		for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+			s.onLog(taskDir, logType, line)
+		}
+	}
+	return nil
+}
+
+// partialFilePatterns match the temporary files yt-dlp and its merger leave
+// behind when a download is interrupted.
+var partialFilePatterns = []string{"*.part", "*.part-Frag*", "*.ytdl", "*.temp.*"}
+
+// RemovePartialFiles deletes incomplete download artifacts and the given
+// stage outputs (relative to taskDir) that may have been partially written.
+func (s *Storage) RemovePartialFiles(taskDir string, outputs ...string) error {
+	var paths []string
+	for _, pattern := range partialFilePatterns {
+		matches, err := filepath.Glob(filepath.Join(taskDir, pattern))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	for _, name := range outputs {
+		paths = append(paths, filepath.Join(taskDir, name))
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
 }
 
 // GetWorkspace returns the current workspace path
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"transcube-webapp/internal/types"
+)
+
+// DefaultSubscriptionInterval is how often subscribed channels are checked
+// for new uploads unless configured otherwise
+const DefaultSubscriptionInterval = time.Hour
+
+// Limits on how much of a channel's upload list is looked at and remembered
+const (
+	subscriptionRecentUploads = 15
+	subscriptionSeenLimit     = 100
+)
+
+// UploadEnqueuer creates and queues a task for a new upload of a subscribed
+// channel, transcribed in the given language
+type UploadEnqueuer func(sub types.Subscription, entry PlaylistEntry, sourceLang string) error
+
+// LanguageResolver returns the language preferred for a channel
+type LanguageResolver func(platform, channelID, channelName string) string
+
+// Subscriptions keeps the list of subscribed channels and periodically queues
+// their new uploads. A video is new when it was published after the channel
+// was subscribed to and has no task in the workspace yet.
+type Subscriptions struct {
+	mu         sync.Mutex
+	checking   sync.Mutex
+	subs       []types.Subscription
+	store      *SettingsStore
+	downloader *Downloader
+	storage    *Storage
+	enqueue    UploadEnqueuer
+	language   LanguageResolver
+}
+
+// NewSubscriptions creates the subscription manager. store may be nil, in
+// which case subscriptions are not persisted.
+func NewSubscriptions(store *SettingsStore, downloader *Downloader, storage *Storage, enqueue UploadEnqueuer, language LanguageResolver) *Subscriptions {
+	return &Subscriptions{
+		store:      store,
+		downloader: downloader,
+		storage:    storage,
+		enqueue:    enqueue,
+		language:   language,
+	}
+}
+
+// Load reads the saved subscriptions
+func (s *Subscriptions) Load() error {
+	if s.store == nil {
+		return nil
+	}
+	subs, err := s.store.LoadSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs = subs
+	return nil
+}
+
+// List returns all subscriptions
+func (s *Subscriptions) List() []types.Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]types.Subscription, len(s.subs))
+	for i, sub := range s.subs {
+		subs[i] = cloneSubscription(sub)
+	}
+	return subs
+}
+
+// Add subscribes to the channel tab at url. The uploads listed right now are
+// marked as seen; only videos published afterwards are queued.
+func (s *Subscriptions) Add(ctx context.Context, url string) (*types.Subscription, error) {
+	if url == "" {
+		return nil, fmt.Errorf("channel url is required")
+	}
+
+	info, err := s.downloader.ListRecentUploads(ctx, url, subscriptionRecentUploads)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := types.Subscription{
+		ID:        uuid.New().String(),
+		URL:       url,
+		Platform:  s.downloader.DetectPlatform(url),
+		Channel:   info.Channel,
+		ChannelID: info.ChannelID,
+		Enabled:   true,
+		CreatedAt: time.Now(),
+	}
+	if sub.Channel == "" {
+		sub.Channel = info.Title
+	}
+	for _, entry := range info.Entries {
+		if entry.IsVideo() {
+			sub.SeenVideoIDs = append(sub.SeenVideoIDs, entry.ID)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.subs {
+		if existing.URL == url || (sub.ChannelID != "" && existing.Platform == sub.Platform && existing.ChannelID == sub.ChannelID) {
+			return nil, fmt.Errorf("already subscribed to %s", existing.Channel)
+		}
+	}
+	s.subs = append(s.subs, sub)
+	if err := s.saveLocked(); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Subscribed to channel", "subscriptionId", sub.ID, "channel", sub.Channel, "url", url)
+	added := cloneSubscription(sub)
+	return &added, nil
+}
+
+// Remove deletes a subscription. Tasks already created for it are kept.
+func (s *Subscriptions) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := slices.IndexFunc(s.subs, func(sub types.Subscription) bool { return sub.ID == id })
+	if index < 0 {
+		return fmt.Errorf("subscription %s not found", id)
+	}
+	s.subs = slices.Delete(s.subs, index, index+1)
+	return s.saveLocked()
+}
+
+// SetEnabled turns automatic checks of a subscription on or off
+func (s *Subscriptions) SetEnabled(id string, enabled bool) (*types.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.subs {
+		if s.subs[i].ID == id {
+			s.subs[i].Enabled = enabled
+			if err := s.saveLocked(); err != nil {
+				return nil, err
+			}
+			updated := cloneSubscription(s.subs[i])
+			return &updated, nil
+		}
+	}
+	return nil, fmt.Errorf("subscription %s not found", id)
+}
+
+// CheckAll looks for new uploads on every enabled subscription and queues
+// them. It returns how many tasks were queued. A failing channel does not
+// stop the others; its error is recorded on the subscription and returned
+// joined with the rest.
+func (s *Subscriptions) CheckAll(ctx context.Context) (int, error) {
+	s.checking.Lock()
+	defer s.checking.Unlock()
+
+	known, err := s.knownVideos()
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	var errs []error
+	for _, sub := range s.List() {
+		if !sub.Enabled {
+			continue
+		}
+		if ctx.Err() != nil {
+			return queued, ctx.Err()
+		}
+		n, err := s.check(ctx, sub, known)
+		queued += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.Channel, err))
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// Run checks the subscriptions right away and then every interval until ctx
+// is done. interval is called before each wait so configuration changes apply
+// to the next check.
+func (s *Subscriptions) Run(ctx context.Context, interval func() time.Duration) {
+	for {
+		queued, err := s.CheckAll(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("Subscription check finished with errors", "queued", queued, "error", err)
+		} else if queued > 0 {
+			slog.Info("Queued new uploads of subscribed channels", "queued", queued)
+		}
+
+		wait := interval()
+		if wait <= 0 {
+			wait = DefaultSubscriptionInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// check queues the new uploads of one subscription. known holds the videos
+// that already have a task, keyed by platform and video ID, and is updated
+// with the videos queued here.
+func (s *Subscriptions) check(ctx context.Context, sub types.Subscription, known map[string]bool) (int, error) {
+	info, err := s.downloader.ListRecentUploads(ctx, sub.URL, subscriptionRecentUploads)
+	if err != nil {
+		s.recordCheck(sub.ID, nil, err)
+		return 0, err
+	}
+
+	channel := sub.Channel
+	if channel == "" {
+		channel = info.Channel
+	}
+	channelID := sub.ChannelID
+	if channelID == "" {
+		channelID = info.ChannelID
+	}
+	lang := ""
+	if s.language != nil {
+		lang = s.language(sub.Platform, channelID, channel)
+	}
+
+	queued := 0
+	var seen []string
+	var errs []error
+	// Entries are newest first; queue the oldest new upload first
+	for _, entry := range slices.Backward(info.Entries) {
+		if !entry.IsVideo() || slices.Contains(sub.SeenVideoIDs, entry.ID) {
+			continue
+		}
+		key := videoKey(sub.Platform, entry.ID)
+		if known[key] {
+			seen = append(seen, entry.ID)
+			continue
+		}
+		if entry.Channel == "" && entry.Uploader == "" {
+			entry.Channel = channel
+		}
+		if err := s.enqueue(sub, entry, lang); err != nil {
+			slog.Warn("Failed to queue upload of subscribed channel", "subscriptionId", sub.ID, "videoId", entry.ID, "error", err)
+			errs = append(errs, fmt.Errorf("queue %s: %w", entry.ID, err))
+			continue
+		}
+		known[key] = true
+		seen = append(seen, entry.ID)
+		queued++
+	}
+
+	err = errors.Join(errs...)
+	s.recordCheck(sub.ID, seen, err)
+	if queued > 0 {
+		slog.Info("Queued new uploads", "subscriptionId", sub.ID, "channel", channel, "queued", queued)
+	}
+	return queued, err
+}
+
+// recordCheck stores the outcome of a check on the subscription
+func (s *Subscriptions) recordCheck(id string, seen []string, checkErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := slices.IndexFunc(s.subs, func(sub types.Subscription) bool { return sub.ID == id })
+	if index < 0 {
+		return // removed while being checked
+	}
+	sub := &s.subs[index]
+	now := time.Now()
+	sub.LastCheckedAt = &now
+	sub.LastError = ""
+	if checkErr != nil {
+		sub.LastError = checkErr.Error()
+	}
+	sub.SeenVideoIDs = append(sub.SeenVideoIDs, seen...)
+	if excess := len(sub.SeenVideoIDs) - subscriptionSeenLimit; excess > 0 {
+		sub.SeenVideoIDs = slices.Delete(sub.SeenVideoIDs, 0, excess)
+	}
+
+	if err := s.saveLocked(); err != nil {
+		slog.Warn("Failed to save subscriptions", "error", err)
+	}
+}
+
+// knownVideos returns the videos that already have a task in the workspace
+func (s *Subscriptions) knownVideos() (map[string]bool, error) {
+	tasks, err := s.storage.GetAllTasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list existing tasks: %w", err)
+	}
+	known := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		known[videoKey(task.Platform, task.VideoID)] = true
+	}
+	return known, nil
+}
+
+func (s *Subscriptions) saveLocked() error {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.SaveSubscriptions(s.subs)
+}
+
+func videoKey(platform, videoID string) string {
+	return platform + ":" + videoID
+}
+
+func cloneSubscription(sub types.Subscription) types.Subscription {
+	if sub.LastCheckedAt != nil {
+		checkedAt := *sub.LastCheckedAt
+		sub.LastCheckedAt = &checkedAt
+	}
+	sub.SeenVideoIDs = slices.Clone(sub.SeenVideoIDs)
+	return sub
+}
@@ -0,0 +1,212 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+// fakeYtDlp installs a yt-dlp stand-in on PATH that prints the JSON stored in
+// the returned file, or fails with a yt-dlp style error when it holds "FAIL".
+// Each invocation's arguments are appended to the returned log.
+func fakeYtDlp(t *testing.T) (listing, argsLog string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	listing = filepath.Join(dir, "listing.json")
+	argsLog = filepath.Join(dir, "args.log")
+	script := `#!/bin/sh
+echo "$@" >> "` + argsLog + `"
+if [ "$(cat "` + listing + `")" = FAIL ]; then
+	echo "ERROR: [youtube:tab] This channel does not exist." >&2
+	exit 1
+fi
+cat "` + listing + `"
+`
+	if err := os.WriteFile(filepath.Join(dir, "yt-dlp"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return listing, argsLog
+}
+
+func writeListing(t *testing.T, path string, ids ...string) {
+	t.Helper()
+
+	var entries []string
+	for _, id := range ids {
+		entries = append(entries, `{"_type": "url", "ie_key": "Youtube", "id": "`+id+`", "url": "https://www.youtube.com/watch?v=`+id+`", "title": "Video `+id+`", "duration": 61}`)
+	}
+	content := `{"_type": "playlist", "id": "UC123", "title": "Gophers - Videos", "channel": "Gophers", "channel_id": "UC123", "entries": [` + strings.Join(entries, ",") + `]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type queuedUpload struct {
+	videoID, channel, lang string
+}
+
+func newTestSubscriptions(t *testing.T, store *SettingsStore, storage *Storage, queued *[]queuedUpload) *Subscriptions {
+	t.Helper()
+
+	enqueue := func(sub types.Subscription, entry PlaylistEntry, lang string) error {
+		*queued = append(*queued, queuedUpload{entry.ID, entry.Channel, lang})
+		return nil
+	}
+	language := func(platform, channelID, channelName string) string {
+		if platform == "youtube" && channelID == "UC123" {
+			return "ja"
+		}
+		return "en"
+	}
+	subs := NewSubscriptions(store, NewDownloader(storage), storage, enqueue, language)
+	if err := subs.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return subs
+}
+
+func TestSubscriptionsQueueNewUploads(t *testing.T) {
+	listing, argsLog := fakeYtDlp(t)
+	store := &SettingsStore{filePath: filepath.Join(t.TempDir(), "settings.json")}
+	storage := NewStorage(t.TempDir())
+	ctx := context.Background()
+
+	var queued []queuedUpload
+	subs := newTestSubscriptions(t, store, storage, &queued)
+
+	writeListing(t, listing, "v3", "v2", "v1")
+	sub, err := subs.Add(ctx, "https://www.youtube.com/@gophers/videos")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if sub.Channel != "Gophers" || sub.ChannelID != "UC123" || sub.Platform != "youtube" {
+		t.Fatalf("unexpected subscription: %+v", sub)
+	}
+	if _, err := subs.Add(ctx, "https://www.youtube.com/@gophers/videos"); err == nil {
+		t.Fatal("expected error when subscribing twice")
+	}
+
+	// v5 was already processed by hand
+	existing := &types.Task{ID: "existing", Platform: "youtube", VideoID: "v5", WorkDir: filepath.Join(storage.GetWorkspace(), "v5")}
+	if err := os.MkdirAll(existing.WorkDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SaveMetadata(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	writeListing(t, listing, "v6", "v5", "v4", "v3", "v2")
+	n, err := subs.CheckAll(ctx)
+	if err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	want := []queuedUpload{{"v4", "Gophers", "ja"}, {"v6", "Gophers", "ja"}}
+	if n != len(want) || !slices.Equal(queued, want) {
+		t.Fatalf("CheckAll() queued %d %v, want %v", n, queued, want)
+	}
+
+	if n, err := subs.CheckAll(ctx); err != nil || n != 0 {
+		t.Fatalf("second CheckAll() = %d, %v; want nothing queued", n, err)
+	}
+
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "--flat-playlist --dump-single-json --playlist-end 15") {
+		t.Fatalf("yt-dlp called with unexpected arguments:\n%s", args)
+	}
+
+	reloaded := newTestSubscriptions(t, store, storage, &queued).List()
+	if len(reloaded) != 1 {
+		t.Fatalf("reloaded %d subscriptions, want 1", len(reloaded))
+	}
+	if reloaded[0].LastCheckedAt == nil {
+		t.Error("LastCheckedAt was not persisted")
+	}
+	for _, id := range []string{"v1", "v4", "v5", "v6"} {
+		if !slices.Contains(reloaded[0].SeenVideoIDs, id) {
+			t.Errorf("video %s missing from persisted seen list %v", id, reloaded[0].SeenVideoIDs)
+		}
+	}
+}
+
+func TestSubscriptionsRecordCheckErrors(t *testing.T) {
+	listing, _ := fakeYtDlp(t)
+	store := &SettingsStore{filePath: filepath.Join(t.TempDir(), "settings.json")}
+	storage := NewStorage(t.TempDir())
+	ctx := context.Background()
+
+	var queued []queuedUpload
+	subs := newTestSubscriptions(t, store, storage, &queued)
+
+	writeListing(t, listing, "v1")
+	sub, err := subs.Add(ctx, "https://www.youtube.com/@gophers/videos")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if err := os.WriteFile(listing, []byte("FAIL"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := subs.CheckAll(ctx); err == nil {
+		t.Fatal("expected CheckAll() to report the failing channel")
+	}
+	if got := subs.List()[0].LastError; got == "" {
+		t.Fatal("LastError was not recorded")
+	}
+
+	if _, err := subs.SetEnabled(sub.ID, false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if n, err := subs.CheckAll(ctx); err != nil || n != 0 {
+		t.Fatalf("CheckAll() with disabled subscription = %d, %v", n, err)
+	}
+
+	if err := subs.Remove(sub.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	saved, err := store.LoadSubscriptions()
+	if err != nil || len(saved) != 0 {
+		t.Fatalf("LoadSubscriptions() after Remove = %v, %v", saved, err)
+	}
+	if len(queued) != 0 {
+		t.Fatalf("nothing should have been queued, got %v", queued)
+	}
+}
+
+func TestSubscriptionsRunChecksAtStartup(t *testing.T) {
+	listing, _ := fakeYtDlp(t)
+	store := &SettingsStore{filePath: filepath.Join(t.TempDir(), "settings.json")}
+	storage := NewStorage(t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var queued []queuedUpload
+	subs := newTestSubscriptions(t, store, storage, &queued)
+
+	writeListing(t, listing, "v1")
+	if _, err := subs.Add(ctx, "https://www.youtube.com/@gophers/videos"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	writeListing(t, listing, "v2", "v1")
+
+	// Stop before the first wait: anything queued was checked at startup
+	subs.Run(ctx, func() time.Duration {
+		cancel()
+		return time.Hour
+	})
+	if want := []queuedUpload{{"v2", "Gophers", "ja"}}; !slices.Equal(queued, want) {
+		t.Fatalf("Run() queued %v before the first interval, want %v", queued, want)
+	}
+}
@@ -3,8 +3,10 @@ package services
 import (
 	"bufio"
 	"fmt"
+	"html"
 	"io"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -61,3 +63,90 @@ func isSubtitleNumber(line string) bool {
 
 	return true
 }
+
+// vttTimingPattern matches a WebVTT cue timing line; the hours are optional
+// and cue settings may follow the end time
+var vttTimingPattern = regexp.MustCompile(`^(?:(\d+):)?(\d{2}):(\d{2})\.(\d{3})\s+-->\s+(?:(\d+):)?(\d{2}):(\d{2})\.(\d{3})`)
+
+// vttTagPattern matches inline cue tags such as <c>, <i> or <00:00:01.000>
+var vttTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ConvertVTTToSRT converts WebVTT captions to SRT. Styling tags are dropped,
+// and lines repeated from the previous cue are removed so that the rolling
+// two-line cues of automatic captions read as plain sentences.
+func ConvertVTTToSRT(reader io.Reader, writer io.Writer) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	index := 0
+	var block, previous []string
+	flush := func() error {
+		defer func() { block = block[:0] }()
+
+		timing := -1
+		for i, line := range block {
+			if vttTimingPattern.MatchString(line) {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			return nil // header, NOTE, STYLE or REGION block
+		}
+		match := vttTimingPattern.FindStringSubmatch(block[timing])
+
+		var lines []string
+		for _, raw := range block[timing+1:] {
+			line := strings.TrimSpace(html.UnescapeString(vttTagPattern.ReplaceAllString(raw, "")))
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		var text []string
+		for _, line := range lines {
+			if !slices.Contains(previous, line) {
+				text = append(text, line)
+			}
+		}
+		previous = lines
+		if len(text) == 0 {
+			return nil
+		}
+
+		index++
+		_, err := fmt.Fprintf(writer, "%d\n%s --> %s\n%s\n\n", index,
+			srtTimestamp(match[1:5]), srtTimestamp(match[5:9]), strings.Join(text, "\n"))
+		return err
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return fmt.Errorf("write subtitle cue: %w", err)
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("write subtitle cue: %w", err)
+	}
+	return nil
+}
+
+// srtTimestamp formats the hours, minutes, seconds and milliseconds captured
+// from a WebVTT timestamp the way SRT expects them
+func srtTimestamp(parts []string) string {
+	hours := parts[0]
+	if hours == "" {
+		hours = "0"
+	}
+	if len(hours) < 2 {
+		hours = "0" + hours
+	}
+	return fmt.Sprintf("%s:%s:%s,%s", hours, parts[1], parts[2], parts[3])
+}
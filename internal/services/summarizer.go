@@ -9,19 +9,51 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"transcube-webapp/internal/types"
 )
 
+// SummaryModel is the OpenRouter model used for structured summaries
+const SummaryModel = "google/gemini-2.5-flash"
+
+// openRouterTimeout bounds one OpenRouter request
+const openRouterTimeout = 60 * time.Second
+
 type OpenRouterClient struct {
+	mu         sync.RWMutex
 	httpClient *http.Client
 }
 
 func NewOpenRouterClient() *OpenRouterClient {
 	return &OpenRouterClient{
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: &http.Client{Timeout: openRouterTimeout},
 	}
 }
 
+// SetProxy routes requests through the given proxy URL; an empty proxy falls
+// back to the proxy set in the environment. Requests already sent keep their
+// connection.
+func (c *OpenRouterClient) SetProxy(proxy string) error {
+	transport, err := newHTTPTransport(proxy)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.httpClient = &http.Client{Timeout: openRouterTimeout, Transport: transport}
+	return nil
+}
+
+func (c *OpenRouterClient) client() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.httpClient
+}
+
 type chatMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
@@ -47,7 +79,7 @@ type jsonSchema struct {
 }
 
 // SummarizeStructured calls OpenRouter with Gemini 2.5 Flash to produce a structured JSON summary
-func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey string, transcript string, length string, language string, temperature float64, maxTokens int) ([]byte, error) {
+func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey string, transcript string, chapters []types.Chapter, length string, language string, temperature float64, maxTokens int) ([]byte, error) {
 	if apiKey == "" {
 		apiKey = os.Getenv("OPENROUTER_API_KEY")
 	}
@@ -91,6 +123,9 @@ func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey strin
 	// Build system / user prompts (content requirements still help quality)
 	system := "You are a precise assistant that summarizes transcripts."
 	user := fmt.Sprintf("Summarize the transcript. Length: %s. Use %s for all text in the summary. Return the object requested by the schema.", length, langName)
+	if len(chapters) > 0 {
+		user += "\n\n" + chapterOutline(chapters)
+	}
 
 	// Define a strict JSON schema to enforce structured output
 	schema := map[string]interface{}{
@@ -123,7 +158,7 @@ func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey strin
 	}
 
 	reqBody := chatReq{
-		Model: "google/gemini-2.5-flash",
+		Model: SummaryModel,
 		Messages: []chatMessage{
 			{Role: "system", Content: system},
 			{Role: "user", Content: user + "\n\nTranscript:\n" + transcript},
@@ -151,9 +186,9 @@ func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey strin
 	req.Header.Set("HTTP-Referer", "https://github.com/strrl/transcube-webapp")
 	req.Header.Set("X-Title", "TransCube")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.client().Do(req)
 	if err != nil {
-		return nil, err
+		return nil, classifyHTTPError(err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -162,7 +197,7 @@ func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey strin
 	}()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("openrouter error: %s: %s", resp.Status, string(b))
+		return nil, classifyOpenRouterStatus(resp.StatusCode, fmt.Errorf("openrouter error: %s: %s", resp.Status, string(b)))
 	}
 
 	// Minimal parse of the OpenAI-compatible response
@@ -186,3 +221,31 @@ func (c *OpenRouterClient) SummarizeStructured(ctx context.Context, apiKey strin
 	// The model is instructed to return a valid JSON object that matches the schema
 	return []byte(parsed.Choices[0].Message.Content), nil
 }
+
+// classifyOpenRouterStatus wraps a failed OpenRouter response with the error
+// class matching its status code
+func classifyOpenRouterStatus(status int, err error) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	case status == http.StatusPaymentRequired ||
+		strings.Contains(strings.ToLower(err.Error()), "quota") ||
+		strings.Contains(strings.ToLower(err.Error()), "credits"):
+		return fmt.Errorf("%w: %v", ErrLLMQuota, err)
+	case status >= 500:
+		return fmt.Errorf("%w: %v", ErrServiceUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// chapterOutline lists the video's chapters for the summary prompt, so the
+// key points follow the structure the video was given
+func chapterOutline(chapters []types.Chapter) string {
+	var b strings.Builder
+	b.WriteString("The video is divided into these chapters. Follow them in order in the key points, covering each chapter that has substance:\n")
+	for _, chapter := range chapters {
+		fmt.Fprintf(&b, "%s %s\n", types.FormatDuration(int(chapter.Start)), chapter.Title)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
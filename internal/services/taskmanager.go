@@ -1,8 +1,12 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,32 +15,63 @@ import (
 	"transcube-webapp/internal/types"
 )
 
+// ErrTaskCancelled is returned by state mutations on a task that has been
+// cancelled, so a stage finishing after the cancellation cannot overwrite it.
+var ErrTaskCancelled = errors.New("task was cancelled")
+
+// ErrTaskPaused is the cause attached to the run context of a paused task. It
+// is also returned by SetTaskError so the interrupted stage cannot fail it.
+var ErrTaskPaused = errors.New("task was paused")
+
+// runStopTimeout bounds how long PauseTask and DeleteTask wait for the run to
+// stop
+const runStopTimeout = 10 * time.Second
+
 type TaskManager struct {
 	mu        sync.RWMutex
 	tasks     map[string]*types.Task
 	taskLocks map[string]*sync.Mutex
+	runs      map[string]context.CancelCauseFunc
+	queue     *TaskQueue
 	storage   *Storage
+	events    *TaskEvents
 }
 
 func NewTaskManager(storage *Storage) *TaskManager {
-	return &TaskManager{
+	tm := &TaskManager{
 		tasks:     make(map[string]*types.Task),
 		taskLocks: make(map[string]*sync.Mutex),
+		runs:      make(map[string]context.CancelCauseFunc),
+		queue:     NewTaskQueue(storage),
 		storage:   storage,
+		events:    NewTaskEvents(),
 	}
+	storage.SetLogHook(tm.publishLog)
+	return tm
+}
+
+// MigrateMetadata rewrites task metadata saved by older versions in the
+// current format. It runs once at startup, holding off every other writer.
+func (tm *TaskManager) MigrateMetadata() (int, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.storage.MigrateMetadata()
 }
 
-// CreateTask creates a new task with pre-fetched metadata and tracks it in memory
-func (tm *TaskManager) CreateTask(url, sourceLang, platform, videoID, title, channel, duration, thumbnail string) (*types.Task, error) {
+// CreateTask creates a new task with pre-fetched metadata and tracks it in
+// memory. The platform and video ID are taken from the metadata.
+func (tm *TaskManager) CreateTask(url, sourceLang string, meta types.VideoMetadata) (*types.Task, error) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
+	platform, videoID := meta.Platform, meta.ID
+
 	if videoID == "" {
 		return nil, fmt.Errorf("video ID is required when creating a task")
 	}
 
 	for _, existing := range tm.tasks {
-		if existing.URL == url && tm.isTaskRunning(existing.Status) {
+		if existing.URL == url && existing.Status.IsActive() {
 			return nil, fmt.Errorf("a task is already running for this url: %s", url)
 		}
 		if existing.Platform == platform && existing.VideoID == videoID {
@@ -64,13 +99,10 @@ func (tm *TaskManager) CreateTask(url, sourceLang, platform, videoID, title, cha
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 		VideoID:    videoID,
-		Title:      title,
-		Channel:    channel,
-		Duration:   duration,
-		Thumbnail:  thumbnail,
 	}
+	setVideoMetadata(task, meta)
 
-	workDir, err := tm.storage.GetTaskDir(title, videoID, task.ID)
+	workDir, err := tm.storage.GetTaskDir(task.Title, videoID, task.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate work directory: %w", err)
 	}
@@ -85,6 +117,7 @@ func (tm *TaskManager) CreateTask(url, sourceLang, platform, videoID, title, cha
 	}
 
 	tm.tasks[task.ID] = task
+	tm.publishLocked(task, false)
 	return cloneTask(task), nil
 }
 
@@ -139,17 +172,34 @@ func (tm *TaskManager) UpdateTaskStatus(taskID string, status types.TaskStatus,
 	if !ok {
 		return fmt.Errorf("task %s not found", taskID)
 	}
+	if task.Status == types.TaskStatusCancelled {
+		return ErrTaskCancelled
+	}
+	if err := types.ValidateTransition(task, status); err != nil {
+		return err
+	}
 
 	task.Status = status
 	task.Progress = progress
+	task.Transfer = nil
 	task.UpdatedAt = time.Now()
 
 	if status == types.TaskStatusDone || status == types.TaskStatusFailed {
+		outcome := types.StageOutcomeSucceeded
+		if status == types.TaskStatusFailed {
+			outcome = types.StageOutcomeFailed
+		}
+		endStageRecord(task, outcome, "", "")
 		now := time.Now()
 		task.CompletedAt = &now
 		go tm.scheduleCleanup(taskID)
+	} else if status == types.TaskStatusPending {
+		// The task is about to run again
+		task.CompletedAt = nil
 	}
 
+	tm.publishLocked(task, false)
+
 	if task.WorkDir != "" {
 		return tm.storage.SaveMetadata(task)
 	}
@@ -157,10 +207,11 @@ func (tm *TaskManager) UpdateTaskStatus(taskID string, status types.TaskStatus,
 	return nil
 }
 
-// BeginStage transitions the task into a new processing stage if the current
-// status is one of the allowed states. It is used to guard manual operations
-// from running concurrently or out of order.
-func (tm *TaskManager) BeginStage(taskID string, stage types.TaskStatus, progress int, allowedStatuses ...types.TaskStatus) error {
+// UpdateTaskProgress records fine-grained progress reported while a stage is
+// running. Progress never moves backwards within a stage. Metadata is only
+// written when the integer progress changes, so frequent transfer updates do
+// not hit the disk.
+func (tm *TaskManager) UpdateTaskProgress(taskID string, stage types.TaskStatus, progress int, transfer *types.TransferStats) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -168,25 +219,91 @@ func (tm *TaskManager) BeginStage(taskID string, stage types.TaskStatus, progres
 	if !ok {
 		return fmt.Errorf("task %s not found", taskID)
 	}
+	if task.Status != stage {
+		if task.Status == types.TaskStatusCancelled {
+			return ErrTaskCancelled
+		}
+		return nil
+	}
 
-	isAllowed := false
-	for _, allowed := range allowedStatuses {
-		if task.Status == allowed {
-			isAllowed = true
+	changed := progress > task.Progress
+	if changed {
+		task.Progress = progress
+	}
+	task.Transfer = transfer
+	task.UpdatedAt = time.Now()
+
+	tm.publishLocked(task, true)
+
+	if changed && task.WorkDir != "" {
+		return tm.storage.SaveMetadata(task)
+	}
+	return nil
+}
+
+// SetEventEmitter sets the function task events are delivered through. emit
+// is called with the manager's lock held and must not call back into it.
+func (tm *TaskManager) SetEventEmitter(emit EmitFunc) {
+	tm.events.SetEmitter(emit)
+}
+
+// publishLocked emits a snapshot of the task to the frontend
+func (tm *TaskManager) publishLocked(task *types.Task, throttle bool) {
+	tm.events.TaskUpdated(cloneTask(task), throttle)
+}
+
+// publishLog forwards a line written to a task log. The task is identified
+// by its work directory.
+func (tm *TaskManager) publishLog(taskDir, logType, line string) {
+	tm.mu.RLock()
+	var taskID string
+	for id, task := range tm.tasks {
+		if task.WorkDir == taskDir {
+			taskID = id
 			break
 		}
 	}
+	tm.mu.RUnlock()
 
-	if !isAllowed {
-		return fmt.Errorf("cannot start %s stage while task is %s", stage, task.Status)
+	if taskID == "" {
+		return
 	}
+	tm.events.TaskLog(TaskLogEntry{TaskID: taskID, Type: logType, Line: line, Time: time.Now()})
+}
 
+// BeginStage transitions the task into a new processing stage if the state
+// machine allows it. It is used to guard manual operations from running
+// concurrently or out of order.
+func (tm *TaskManager) BeginStage(taskID string, stage types.TaskStatus, progress int) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, ok := tm.tasks[taskID]
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if task.Status == types.TaskStatusCancelled {
+		return ErrTaskCancelled
+	}
+	if task.Status == stage {
+		return fmt.Errorf("%w: %s stage is already in progress", types.ErrInvalidTransition, stage)
+	}
+	if err := types.ValidateTransition(task, stage); err != nil {
+		return err
+	}
+
+	startStageRecord(task, stage)
 	task.Status = stage
+	task.PausedStage = ""
 	task.Progress = progress
+	task.Transfer = nil
 	task.Error = ""
+	task.ErrorCode = ""
 	task.CompletedAt = nil
 	task.UpdatedAt = time.Now()
 
+	tm.publishLocked(task, false)
+
 	if task.WorkDir != "" {
 		if err := tm.storage.SaveMetadata(task); err != nil {
 			return fmt.Errorf("failed to persist task metadata: %w", err)
@@ -196,8 +313,10 @@ func (tm *TaskManager) BeginStage(taskID string, stage types.TaskStatus, progres
 	return nil
 }
 
-// UpdateTaskMetadata updates the metadata for the given task
-func (tm *TaskManager) UpdateTaskMetadata(taskID, videoID, title, channel, duration, thumbnail string) error {
+// UpdateTaskMetadata replaces the video metadata of the given task. The
+// title, channel, duration and thumbnail keep their current value when meta
+// lacks them; the platform never changes.
+func (tm *TaskManager) UpdateTaskMetadata(taskID string, meta types.VideoMetadata) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -206,7 +325,7 @@ func (tm *TaskManager) UpdateTaskMetadata(taskID, videoID, title, channel, durat
 		return fmt.Errorf("task %s not found", taskID)
 	}
 
-	if videoID != "" && task.VideoID != videoID {
+	if videoID := meta.ID; videoID != "" && task.VideoID != videoID {
 		for id, existing := range tm.tasks {
 			if id == taskID {
 				continue
@@ -232,18 +351,7 @@ func (tm *TaskManager) UpdateTaskMetadata(taskID, videoID, title, channel, durat
 		task.VideoID = videoID
 	}
 
-	if title != "" {
-		task.Title = title
-	}
-	if channel != "" {
-		task.Channel = channel
-	}
-	if duration != "" {
-		task.Duration = duration
-	}
-	if thumbnail != "" {
-		task.Thumbnail = thumbnail
-	}
+	setVideoMetadata(task, meta)
 	task.UpdatedAt = time.Now()
 
 	if task.WorkDir == "" {
@@ -257,6 +365,8 @@ func (tm *TaskManager) UpdateTaskMetadata(taskID, videoID, title, channel, durat
 		task.WorkDir = targetDir
 	}
 
+	tm.publishLocked(task, false)
+
 	if err := tm.storage.SaveMetadata(task); err != nil {
 		return fmt.Errorf("failed to persist task metadata: %w", err)
 	}
@@ -277,6 +387,8 @@ func (tm *TaskManager) UpdateTaskSourceLang(taskID string, sourceLang string) (*
 	task.SourceLang = sourceLang
 	task.UpdatedAt = time.Now()
 
+	tm.publishLocked(task, false)
+
 	if task.WorkDir != "" {
 		if err := tm.storage.SaveMetadata(task); err != nil {
 			return nil, fmt.Errorf("failed to persist task metadata: %w", err)
@@ -286,8 +398,93 @@ func (tm *TaskManager) UpdateTaskSourceLang(taskID string, sourceLang string) (*
 	return cloneTask(task), nil
 }
 
-// SetTaskError marks the task as failed with the provided error message
-func (tm *TaskManager) SetTaskError(taskID string, err string) error {
+// SetTaskCollection records the collection the task was created from
+func (tm *TaskManager) SetTaskCollection(taskID string, collectionID string) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.CollectionID = collectionID
+	})
+}
+
+// SetTaskSourceFile records the media file a local task was imported from
+func (tm *TaskManager) SetTaskSourceFile(taskID string, sourceFile string) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.SourceFile = sourceFile
+	})
+}
+
+// SetTaskCaptions records the caption track downloaded for the task, or
+// clears it when captions is nil
+func (tm *TaskManager) SetTaskCaptions(taskID string, captions *types.Captions) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.Captions = captions
+	})
+}
+
+// SetTranscriptSource records where the task's transcript came from
+func (tm *TaskManager) SetTranscriptSource(taskID string, source types.TranscriptSource) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.TranscriptSource = source
+	})
+}
+
+// SetTaskDownloadMode overrides the download mode from settings for the
+// task; an empty mode clears the override
+func (tm *TaskManager) SetTaskDownloadMode(taskID string, mode types.DownloadMode) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.DownloadMode = mode
+	})
+}
+
+// SetTaskFormatPreset selects the format preset the task is downloaded in;
+// an empty name makes it use the default preset from settings
+func (tm *TaskManager) SetTaskFormatPreset(taskID string, name string) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.FormatPreset = name
+	})
+}
+
+// SetTaskFormat records what the download stage fetched for the task
+func (tm *TaskManager) SetTaskFormat(taskID string, format types.DownloadedFormat) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.Format = &format
+	})
+}
+
+// SetTaskForceASR sets whether the task is transcribed with yap even when
+// captions are available
+func (tm *TaskManager) SetTaskForceASR(taskID string, force bool) (*types.Task, error) {
+	return tm.updateTask(taskID, func(task *types.Task) {
+		task.ForceASR = force
+	})
+}
+
+// updateTask applies fn to the task, then publishes and persists the change
+func (tm *TaskManager) updateTask(taskID string, fn func(task *types.Task)) (*types.Task, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, ok := tm.tasks[taskID]
+	if !ok {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+
+	fn(task)
+	task.UpdatedAt = time.Now()
+
+	tm.publishLocked(task, false)
+
+	if task.WorkDir != "" {
+		if err := tm.storage.SaveMetadata(task); err != nil {
+			return nil, fmt.Errorf("failed to persist task metadata: %w", err)
+		}
+	}
+
+	return cloneTask(task), nil
+}
+
+// SetTaskError marks the task as failed with the provided error code and
+// message
+func (tm *TaskManager) SetTaskError(taskID string, code types.ErrorCode, err string) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -295,14 +492,28 @@ func (tm *TaskManager) SetTaskError(taskID string, err string) error {
 	if !ok {
 		return fmt.Errorf("task %s not found", taskID)
 	}
+	if task.Status == types.TaskStatusCancelled {
+		return ErrTaskCancelled
+	}
+	if task.Status == types.TaskStatusPaused {
+		return ErrTaskPaused
+	}
+	if err := types.ValidateTransition(task, types.TaskStatusFailed); err != nil {
+		return err
+	}
 
+	endStageRecord(task, types.StageOutcomeFailed, err, code)
 	task.Status = types.TaskStatusFailed
+	task.Transfer = nil
 	task.Error = err
+	task.ErrorCode = code
 	task.UpdatedAt = time.Now()
 	now := time.Now()
 	task.CompletedAt = &now
 	go tm.scheduleCleanup(taskID)
 
+	tm.publishLocked(task, false)
+
 	if task.WorkDir != "" {
 		return tm.storage.SaveMetadata(task)
 	}
@@ -310,7 +521,121 @@ func (tm *TaskManager) SetTaskError(taskID string, err string) error {
 	return nil
 }
 
-// RetryTask resets task state if it previously failed
+// CancelTask marks the task as cancelled and aborts its active run, if any.
+// The run's context is cancelled, which terminates any child process started
+// with it; the pipeline is responsible for cleaning up partial files.
+func (tm *TaskManager) CancelTask(taskID string) (*types.Task, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, ok := tm.tasks[taskID]
+	if !ok {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+
+	if task.Status == types.TaskStatusCancelled {
+		return nil, fmt.Errorf("task is already cancelled")
+	}
+	if err := types.ValidateTransition(task, types.TaskStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	endStageRecord(task, types.StageOutcomeCancelled, "", "")
+	task.Status = types.TaskStatusCancelled
+	task.Transfer = nil
+	task.Error = ""
+	task.ErrorCode = ""
+	now := time.Now()
+	task.UpdatedAt = now
+	task.CompletedAt = &now
+
+	if cancel, ok := tm.runs[taskID]; ok {
+		cancel(ErrTaskCancelled)
+	}
+	tm.Dequeue(taskID)
+	go tm.scheduleCleanup(taskID)
+
+	tm.publishLocked(task, false)
+
+	if task.WorkDir != "" {
+		if err := tm.storage.SaveMetadata(task); err != nil {
+			return nil, fmt.Errorf("failed to persist task metadata: %w", err)
+		}
+	}
+
+	return cloneTask(task), nil
+}
+
+// PauseTask stops the task's active run, if any, and records the stage it
+// should resume from. Unlike cancellation, partial downloads are kept so the
+// stage can pick up where it left off. PauseTask waits for the run to stop.
+func (tm *TaskManager) PauseTask(taskID string) (*types.Task, error) {
+	tm.mu.Lock()
+
+	task, ok := tm.tasks[taskID]
+	if !ok {
+		tm.mu.Unlock()
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	if task.Status == types.TaskStatusPaused {
+		tm.mu.Unlock()
+		return nil, fmt.Errorf("task is already paused")
+	}
+	if err := types.ValidateTransition(task, types.TaskStatusPaused); err != nil {
+		tm.mu.Unlock()
+		return nil, err
+	}
+
+	switch task.Status {
+	case types.TaskStatusDownloading, types.TaskStatusTranscribing, types.TaskStatusSummarizing:
+		task.PausedStage = task.Status
+	default:
+		task.PausedStage = types.StageForProgress(task.Progress)
+	}
+	endStageRecord(task, types.StageOutcomePaused, "", "")
+	task.Status = types.TaskStatusPaused
+	task.Transfer = nil
+	task.UpdatedAt = time.Now()
+
+	if cancel, ok := tm.runs[taskID]; ok {
+		cancel(ErrTaskPaused)
+	}
+
+	tm.publishLocked(task, false)
+
+	var err error
+	if task.WorkDir != "" {
+		err = tm.storage.SaveMetadata(task)
+	}
+	snapshot := cloneTask(task)
+	tm.mu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist task metadata: %w", err)
+	}
+	if !tm.waitForRelease(taskID, runStopTimeout) {
+		slog.Warn("Paused task is still stopping", "taskId", taskID)
+	}
+	return snapshot, nil
+}
+
+// waitForRelease waits until no operation holds the task lock
+func (tm *TaskManager) waitForRelease(taskID string, timeout time.Duration) bool {
+	lock := tm.getTaskLock(taskID)
+	deadline := time.Now().Add(timeout)
+	for {
+		if lock.TryLock() {
+			lock.Unlock()
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// RetryTask resets task state if it previously failed or was cancelled
 func (tm *TaskManager) RetryTask(taskID string) (*types.Task, error) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
@@ -320,19 +645,143 @@ func (tm *TaskManager) RetryTask(taskID string) (*types.Task, error) {
 		return nil, fmt.Errorf("task %s not found", taskID)
 	}
 
-	if task.Status != types.TaskStatusFailed {
-		return nil, fmt.Errorf("can only retry failed tasks")
+	if task.Status != types.TaskStatusFailed && task.Status != types.TaskStatusCancelled {
+		return nil, fmt.Errorf("can only retry failed or cancelled tasks")
+	}
+	if err := types.ValidateTransition(task, types.TaskStatusPending); err != nil {
+		return nil, err
 	}
 
 	task.Status = types.TaskStatusPending
 	task.Progress = 0
 	task.Error = ""
+	task.ErrorCode = ""
 	task.CompletedAt = nil
+	task.RecoveryAttempts = 0
+	task.UpdatedAt = time.Now()
+
+	tm.publishLocked(task, false)
+
+	return cloneTask(task), nil
+}
+
+// Enqueue appends the task to the processing queue and marks it as queued.
+// The queue entry is added first so a task is never left queued in its
+// metadata without a place in the queue.
+func (tm *TaskManager) Enqueue(taskID string) (*types.Task, error) {
+	if err := tm.queue.Add(taskID); err != nil {
+		return nil, fmt.Errorf("failed to persist task queue: %w", err)
+	}
+	task, err := tm.setQueued(taskID)
+	if err != nil {
+		tm.Dequeue(taskID)
+		return nil, err
+	}
+	return task, nil
+}
+
+// Dequeue removes the task from the processing queue
+func (tm *TaskManager) Dequeue(taskID string) {
+	if err := tm.queue.Remove(taskID); err != nil {
+		slog.Warn("Failed to persist task queue", "taskId", taskID, "error", err)
+	}
+}
+
+// WaitForStage blocks until the queue grants the task a worker slot for the
+// given stage. While it waits the task is reported as queued. The returned
+// function releases the slot and must be called once the stage finishes.
+func (tm *TaskManager) WaitForStage(ctx context.Context, taskID string, stage types.TaskStatus) (func(), error) {
+	if release, ok := tm.queue.TryAcquire(stage, taskID); ok {
+		return release, nil
+	}
+
+	if _, err := tm.setQueued(taskID); err != nil {
+		return nil, err
+	}
+	return tm.queue.Acquire(ctx, stage, taskID)
+}
+
+// MoveQueuedTask moves the task to the given zero-based position in the queue
+func (tm *TaskManager) MoveQueuedTask(taskID string, position int) error {
+	return tm.queue.Move(taskID, position)
+}
+
+// QueuedTaskIDs returns the IDs of queued and in-flight tasks in queue order
+func (tm *TaskManager) QueuedTaskIDs() []string {
+	return tm.queue.IDs()
+}
+
+// LoadQueue restores the persisted queue order and returns it
+func (tm *TaskManager) LoadQueue() ([]string, error) {
+	return tm.queue.Load()
+}
+
+// SetStageConcurrency sets how many tasks may run the stage at once.
+// Non-positive values restore the default.
+func (tm *TaskManager) SetStageConcurrency(stage types.TaskStatus, workers int) {
+	tm.queue.SetLimit(stage, workers)
+}
+
+// setQueued moves the task into the queued status, keeping its progress
+func (tm *TaskManager) setQueued(taskID string) (*types.Task, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, ok := tm.tasks[taskID]
+	if !ok {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	if task.Status == types.TaskStatusCancelled {
+		return nil, ErrTaskCancelled
+	}
+	if err := types.ValidateTransition(task, types.TaskStatusQueued); err != nil {
+		return nil, err
+	}
+
+	// A stage still open here never finished, e.g. the app quit during it
+	endStageRecord(task, types.StageOutcomeInterrupted, "", "")
+	task.Status = types.TaskStatusQueued
 	task.UpdatedAt = time.Now()
 
+	tm.publishLocked(task, false)
+
+	if task.WorkDir != "" {
+		if err := tm.storage.SaveMetadata(task); err != nil {
+			return nil, fmt.Errorf("failed to persist task metadata: %w", err)
+		}
+	}
+
 	return cloneTask(task), nil
 }
 
+// DeleteTask cancels the task if it is still waiting or running, waits for
+// its run to release the task and forgets it. The caller removes the task
+// files afterwards, so a stopping stage cannot write into a deleted directory.
+func (tm *TaskManager) DeleteTask(taskID string) error {
+	tm.mu.RLock()
+	task, ok := tm.tasks[taskID]
+	active := ok && !task.Status.IsTerminal()
+	tm.mu.RUnlock()
+
+	if active {
+		if _, err := tm.CancelTask(taskID); err != nil {
+			slog.Warn("Failed to cancel deleted task", "taskId", taskID, "error", err)
+		}
+	}
+	tm.mu.Lock()
+	if cancel, ok := tm.runs[taskID]; ok {
+		cancel(ErrTaskCancelled)
+	}
+	tm.mu.Unlock()
+	tm.Dequeue(taskID)
+
+	if !tm.waitForRelease(taskID, runStopTimeout) {
+		return fmt.Errorf("task %s is still stopping, try again shortly", taskID)
+	}
+	tm.ClearTask(taskID)
+	return nil
+}
+
 // ClearTask removes the task from the in-memory map
 func (tm *TaskManager) ClearTask(taskID string) {
 	tm.mu.Lock()
@@ -340,17 +789,10 @@ func (tm *TaskManager) ClearTask(taskID string) {
 
 	delete(tm.tasks, taskID)
 	delete(tm.taskLocks, taskID)
-}
-
-// isTaskRunning checks if a task status indicates it's still running
-func (tm *TaskManager) isTaskRunning(status types.TaskStatus) bool {
-	switch status {
-	case types.TaskStatusPending, types.TaskStatusDownloading,
-		types.TaskStatusTranscribing, types.TaskStatusTranslating,
-		types.TaskStatusSummarizing:
-		return true
-	default:
-		return false
+	tm.events.Forget(taskID)
+	if cancel, ok := tm.runs[taskID]; ok {
+		cancel(nil)
+		delete(tm.runs, taskID)
 	}
 }
 
@@ -364,18 +806,65 @@ func (tm *TaskManager) scheduleCleanup(taskID string) {
 			return
 		}
 
-		if task.Status == types.TaskStatusDone || task.Status == types.TaskStatusFailed {
+		if task.Status.IsTerminal() {
 			delete(tm.tasks, taskID)
 			delete(tm.taskLocks, taskID)
+			tm.events.Forget(taskID)
 		}
 	})
 }
 
+// setVideoMetadata copies meta onto the task's title, channel, duration and
+// thumbnail, keeping those meta lacks, and stores it as the task's Metadata.
+// The task's video ID and platform are left to the caller.
+func setVideoMetadata(task *types.Task, meta types.VideoMetadata) {
+	if meta.Title != "" {
+		task.Title = meta.Title
+	}
+	if meta.Channel != "" {
+		task.Channel = meta.Channel
+	}
+	if meta.Duration > 0 || task.Duration == "" {
+		task.DurationSeconds = meta.Duration
+		task.Duration = types.FormatDuration(meta.Duration)
+	}
+	if meta.Thumbnail != "" {
+		task.Thumbnail = meta.Thumbnail
+	}
+
+	meta.ID = task.VideoID
+	meta.Platform = task.Platform
+	meta.Title = task.Title
+	meta.Channel = task.Channel
+	meta.Duration = task.DurationSeconds
+	meta.Thumbnail = task.Thumbnail
+	meta.Tags = slices.Clone(meta.Tags)
+	task.Metadata = &meta
+}
+
 func cloneTask(task *types.Task) *types.Task {
 	if task == nil {
 		return nil
 	}
 	copy := *task
+	if task.Transfer != nil {
+		transfer := *task.Transfer
+		copy.Transfer = &transfer
+	}
+	if task.Captions != nil {
+		captions := *task.Captions
+		copy.Captions = &captions
+	}
+	if task.Format != nil {
+		format := *task.Format
+		copy.Format = &format
+	}
+	if task.Metadata != nil {
+		metadata := *task.Metadata
+		metadata.Tags = slices.Clone(task.Metadata.Tags)
+		copy.Metadata = &metadata
+	}
+	copy.Stages = cloneStageRecords(task.Stages)
 	return &copy
 }
 
@@ -391,20 +880,35 @@ func (tm *TaskManager) getTaskLock(taskID string) *sync.Mutex {
 }
 
 // LockTask acquires the operation lock for a task, preventing concurrent operations.
-// Returns an error if the task is already locked.
-func (tm *TaskManager) LockTask(taskID string) error {
+// Returns an error if the task is already locked. The returned context is
+// cancelled by CancelTask or when the lock is released, and should be passed to
+// every subprocess started while the lock is held.
+func (tm *TaskManager) LockTask(taskID string) (context.Context, error) {
 	lock := tm.getTaskLock(taskID)
 
 	// Try to acquire the lock without blocking
 	if !lock.TryLock() {
-		return fmt.Errorf("task %s is already being processed", taskID)
+		return nil, fmt.Errorf("task %s is already being processed", taskID)
 	}
 
-	return nil
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	tm.mu.Lock()
+	tm.runs[taskID] = cancel
+	tm.mu.Unlock()
+
+	return ctx, nil
 }
 
-// UnlockTask releases the operation lock for a task
+// UnlockTask releases the operation lock for a task and cancels its run context
 func (tm *TaskManager) UnlockTask(taskID string) {
+	tm.mu.Lock()
+	if cancel, ok := tm.runs[taskID]; ok {
+		cancel(nil)
+		delete(tm.runs, taskID)
+	}
+	tm.mu.Unlock()
+
 	lock := tm.getTaskLock(taskID)
 	lock.Unlock()
 }
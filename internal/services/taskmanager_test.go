@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestUpdateTaskStatusClearsCompletedAtOnRerun(t *testing.T) {
+	tm := NewTaskManager(NewStorage(t.TempDir()))
+	task, err := tm.CreateTask("https://www.youtube.com/watch?v=dQw4w9WgXcQ", "en", types.VideoMetadata{ID: "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range []types.TaskStatus{types.TaskStatusQueued, types.TaskStatusDone} {
+		if err := tm.UpdateTaskStatus(task.ID, status, types.ProgressTaskComplete); err != nil {
+			t.Fatalf("UpdateTaskStatus(%s) error = %v", status, err)
+		}
+	}
+	if task, _ = tm.GetTask(task.ID); task.CompletedAt == nil {
+		t.Fatal("CompletedAt not set on a finished task")
+	}
+
+	if err := tm.UpdateTaskStatus(task.ID, types.TaskStatusPending, types.ProgressTranscribeComplete); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ = tm.GetTask(task.ID); task.CompletedAt != nil {
+		t.Errorf("CompletedAt = %v on a task that runs again, want nil", task.CompletedAt)
+	}
+}
+
+func TestCancelTaskDequeues(t *testing.T) {
+	tm := NewTaskManager(NewStorage(t.TempDir()))
+	task, err := tm.CreateTask("https://www.youtube.com/watch?v=dQw4w9WgXcQ", "en", types.VideoMetadata{ID: "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.Enqueue(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tm.CancelTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ids := tm.QueuedTaskIDs(); len(ids) != 0 {
+		t.Errorf("queue = %v after cancelling, want it empty", ids)
+	}
+}
+
+func TestDeleteTaskStopsRun(t *testing.T) {
+	tm := NewTaskManager(NewStorage(t.TempDir()))
+	task, err := tm.CreateTask("https://www.youtube.com/watch?v=dQw4w9WgXcQ", "en", types.VideoMetadata{ID: "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.Enqueue(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := tm.LockTask(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stopped atomic.Bool
+	go func() {
+		<-ctx.Done()
+		// A stage takes a moment to kill its tool and clean up
+		time.Sleep(100 * time.Millisecond)
+		stopped.Store(true)
+		tm.UnlockTask(task.ID)
+	}()
+
+	if err := tm.DeleteTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !stopped.Load() {
+		t.Fatal("DeleteTask returned before the run released the task")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrTaskCancelled) {
+		t.Errorf("run stopped with %v, want ErrTaskCancelled", cause)
+	}
+	if _, err := tm.GetTask(task.ID); err == nil {
+		t.Error("deleted task is still tracked")
+	}
+	if ids := tm.QueuedTaskIDs(); len(ids) != 0 {
+		t.Errorf("queue = %v after deleting, want it empty", ids)
+	}
+}
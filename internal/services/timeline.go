@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+// FinishStage closes the task's open record for stage with the given outcome.
+// toolVersions and stageErr are optional.
+func (tm *TaskManager) FinishStage(taskID string, stage types.TaskStatus, outcome types.StageOutcome, toolVersions map[string]string, stageErr error) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, ok := tm.tasks[taskID]
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	record := openStageRecord(task)
+	if record == nil || record.Stage != stage {
+		return nil
+	}
+	if stageErr != nil {
+		closeStageRecord(record, outcome, stageErr.Error(), ErrorCodeOf(stageErr))
+	} else {
+		closeStageRecord(record, outcome, "", "")
+	}
+	if len(toolVersions) > 0 {
+		record.ToolVersions = maps.Clone(toolVersions)
+	}
+	task.UpdatedAt = time.Now()
+
+	tm.publishLocked(task, false)
+
+	if task.WorkDir != "" {
+		return tm.storage.SaveMetadata(task)
+	}
+	return nil
+}
+
+// startStageRecord appends a running record for stage, closing any record
+// left open by an attempt that never reported its end
+func startStageRecord(task *types.Task, stage types.TaskStatus) {
+	if open := openStageRecord(task); open != nil {
+		closeStageRecord(open, types.StageOutcomeInterrupted, "", "")
+	}
+
+	attempt := 1
+	for _, record := range task.Stages {
+		if record.Stage == stage {
+			attempt++
+		}
+	}
+	task.Stages = append(task.Stages, types.StageRecord{
+		Stage:     stage,
+		Attempt:   attempt,
+		StartedAt: time.Now(),
+		Outcome:   types.StageOutcomeRunning,
+	})
+}
+
+// endStageRecord closes the open record, if any, with the given outcome and
+// optional error
+func endStageRecord(task *types.Task, outcome types.StageOutcome, message string, code types.ErrorCode) {
+	if open := openStageRecord(task); open != nil {
+		closeStageRecord(open, outcome, message, code)
+	}
+}
+
+// openStageRecord returns the record of the stage attempt still running
+func openStageRecord(task *types.Task) *types.StageRecord {
+	if len(task.Stages) == 0 {
+		return nil
+	}
+	last := &task.Stages[len(task.Stages)-1]
+	if last.Outcome != types.StageOutcomeRunning {
+		return nil
+	}
+	return last
+}
+
+func closeStageRecord(record *types.StageRecord, outcome types.StageOutcome, message string, code types.ErrorCode) {
+	now := time.Now()
+	record.EndedAt = &now
+	record.DurationMs = now.Sub(record.StartedAt).Milliseconds()
+	record.Outcome = outcome
+	record.Error = message
+	record.ErrorCode = code
+}
+
+func cloneStageRecords(records []types.StageRecord) []types.StageRecord {
+	if records == nil {
+		return nil
+	}
+	cloned := make([]types.StageRecord, len(records))
+	for i, record := range records {
+		if record.EndedAt != nil {
+			endedAt := *record.EndedAt
+			record.EndedAt = &endedAt
+		}
+		record.ToolVersions = maps.Clone(record.ToolVersions)
+		cloned[i] = record
+	}
+	return cloned
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestStartStageRecord(t *testing.T) {
+	task := &types.Task{}
+
+	startStageRecord(task, types.TaskStatusDownloading)
+	startStageRecord(task, types.TaskStatusTranscribing)
+	endStageRecord(task, types.StageOutcomeFailed, "yap exited", types.ErrorCodeASRFailed)
+	startStageRecord(task, types.TaskStatusTranscribing)
+
+	want := []struct {
+		stage   types.TaskStatus
+		attempt int
+		outcome types.StageOutcome
+	}{
+		{types.TaskStatusDownloading, 1, types.StageOutcomeInterrupted},
+		{types.TaskStatusTranscribing, 1, types.StageOutcomeFailed},
+		{types.TaskStatusTranscribing, 2, types.StageOutcomeRunning},
+	}
+	if len(task.Stages) != len(want) {
+		t.Fatalf("got %d records, want %d", len(task.Stages), len(want))
+	}
+	for i, w := range want {
+		record := task.Stages[i]
+		if record.Stage != w.stage || record.Attempt != w.attempt || record.Outcome != w.outcome {
+			t.Errorf("record %d = %s attempt %d %s; want %s attempt %d %s", i, record.Stage, record.Attempt, record.Outcome, w.stage, w.attempt, w.outcome)
+		}
+		if (record.EndedAt == nil) != (w.outcome == types.StageOutcomeRunning) {
+			t.Errorf("record %d EndedAt = %v with outcome %s", i, record.EndedAt, record.Outcome)
+		}
+	}
+	if failed := task.Stages[1]; failed.Error != "yap exited" || failed.ErrorCode != types.ErrorCodeASRFailed {
+		t.Errorf("failed record error = %q, %q", failed.Error, failed.ErrorCode)
+	}
+	if open := openStageRecord(task); open != &task.Stages[2] {
+		t.Errorf("openStageRecord() = %v, want the last record", open)
+	}
+}
+
+func TestEndStageRecordWithoutOpenRecord(t *testing.T) {
+	task := &types.Task{}
+	endStageRecord(task, types.StageOutcomeSucceeded, "", "")
+	if len(task.Stages) != 0 {
+		t.Fatalf("records = %v, want none", task.Stages)
+	}
+
+	startStageRecord(task, types.TaskStatusSummarizing)
+	endStageRecord(task, types.StageOutcomeSucceeded, "", "")
+	endedAt := task.Stages[0].EndedAt
+	endStageRecord(task, types.StageOutcomeFailed, "late", types.ErrorCodeLLMQuota)
+	if record := task.Stages[0]; record.Outcome != types.StageOutcomeSucceeded || record.EndedAt != endedAt || record.Error != "" {
+		t.Errorf("closed record changed to %+v", record)
+	}
+	if openStageRecord(task) != nil {
+		t.Error("openStageRecord() returned a closed record")
+	}
+}
+
+func TestCloseStageRecord(t *testing.T) {
+	record := types.StageRecord{
+		Stage:     types.TaskStatusDownloading,
+		StartedAt: time.Now().Add(-1500 * time.Millisecond),
+		Outcome:   types.StageOutcomeRunning,
+	}
+	closeStageRecord(&record, types.StageOutcomeFailed, "HTTP Error 429", types.ErrorCodeRateLimited)
+
+	if record.EndedAt == nil || record.DurationMs < 1500 || record.DurationMs > 60_000 {
+		t.Errorf("EndedAt %v, DurationMs %d; want about 1500ms", record.EndedAt, record.DurationMs)
+	}
+	if record.Outcome != types.StageOutcomeFailed || record.Error != "HTTP Error 429" || record.ErrorCode != types.ErrorCodeRateLimited {
+		t.Errorf("closed record = %+v", record)
+	}
+}
+
+func TestFinishStage(t *testing.T) {
+	tm := NewTaskManager(NewStorage(t.TempDir()))
+	task, err := tm.CreateTask("https://www.youtube.com/watch?v=dQw4w9WgXcQ", "en", types.VideoMetadata{ID: "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.BeginStage(task.ID, types.TaskStatusDownloading, types.ProgressDownloadStart); err != nil {
+		t.Fatal(err)
+	}
+
+	// A record for a different stage is left alone
+	if err := tm.FinishStage(task.ID, types.TaskStatusTranscribing, types.StageOutcomeSucceeded, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ = tm.GetTask(task.ID); task.Stages[0].Outcome != types.StageOutcomeRunning {
+		t.Fatalf("download record closed by another stage: %s", task.Stages[0].Outcome)
+	}
+
+	versions := map[string]string{"yt-dlp": "2025.01.01"}
+	stageErr := fmt.Errorf("%w: 2.1 GiB", ErrFileTooLarge)
+	if err := tm.FinishStage(task.ID, types.TaskStatusDownloading, types.StageOutcomeFailed, versions, stageErr); err != nil {
+		t.Fatal(err)
+	}
+	versions["yt-dlp"] = "changed"
+
+	task, _ = tm.GetTask(task.ID)
+	record := task.Stages[0]
+	if record.Outcome != types.StageOutcomeFailed || record.Error != stageErr.Error() || record.ErrorCode != types.ErrorCodeFileTooLarge {
+		t.Errorf("finished record = %+v", record)
+	}
+	if want := map[string]string{"yt-dlp": "2025.01.01"}; !reflect.DeepEqual(record.ToolVersions, want) {
+		t.Errorf("ToolVersions = %v, want a copy of %v", record.ToolVersions, want)
+	}
+
+	if err := tm.FinishStage("missing", types.TaskStatusDownloading, types.StageOutcomeSucceeded, nil, nil); err == nil {
+		t.Error("FinishStage() of an unknown task succeeded")
+	}
+}
+
+func TestUpdateTaskStatusClosesStageRecord(t *testing.T) {
+	tests := []struct {
+		status types.TaskStatus
+		want   types.StageOutcome
+	}{
+		{types.TaskStatusDone, types.StageOutcomeSucceeded},
+		{types.TaskStatusFailed, types.StageOutcomeFailed},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			tm := NewTaskManager(NewStorage(t.TempDir()))
+			task, err := tm.CreateTask("https://www.youtube.com/watch?v=dQw4w9WgXcQ", "en", types.VideoMetadata{ID: "dQw4w9WgXcQ"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tm.UpdateTaskStatus(task.ID, types.TaskStatusQueued, types.ProgressTranscribeComplete); err != nil {
+				t.Fatal(err)
+			}
+			if err := tm.BeginStage(task.ID, types.TaskStatusSummarizing, types.ProgressTranscribeComplete); err != nil {
+				t.Fatal(err)
+			}
+			if err := tm.UpdateTaskStatus(task.ID, tt.status, types.ProgressTaskComplete); err != nil {
+				t.Fatal(err)
+			}
+			if task, _ = tm.GetTask(task.ID); task.Stages[0].Outcome != tt.want {
+				t.Errorf("record outcome = %s, want %s", task.Stages[0].Outcome, tt.want)
+			}
+		})
+	}
+}
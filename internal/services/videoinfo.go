@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// InfoFile is the name yt-dlp's full metadata of the video is saved under in
+// the task directory
+const InfoFile = "info.json"
+
+// Metadata fetched for a URL is reused for infoCacheTTL, which covers the
+// preview and creation of a task and the start of its download. The cache
+// holds at most maxInfoCacheEntries videos since every entry keeps the full
+// yt-dlp output, format list included.
+const (
+	infoCacheTTL        = 10 * time.Minute
+	maxInfoCacheEntries = 32
+)
+
+// infoCache keeps recently fetched video metadata by canonical URL
+type infoCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]infoCacheEntry
+}
+
+type infoCacheEntry struct {
+	info      VideoInfo
+	fetchedAt time.Time
+}
+
+func newInfoCache(ttl time.Duration) *infoCache {
+	return &infoCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]infoCacheEntry),
+	}
+}
+
+// get returns a copy of the metadata cached for key, if it has not expired
+func (c *infoCache) get(key string) (*VideoInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().Sub(entry.fetchedAt) >= c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+	info := entry.info
+	return &info, true
+}
+
+// put caches a copy of info for key, dropping expired entries and, when the
+// cache is full, the oldest one
+func (c *infoCache) put(key string, info *VideoInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	oldest := ""
+	for k, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= c.ttl {
+			delete(c.entries, k)
+			continue
+		}
+		if oldest == "" || entry.fetchedAt.Before(c.entries[oldest].fetchedAt) {
+			oldest = k
+		}
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxInfoCacheEntries {
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = infoCacheEntry{info: *info, fetchedAt: now}
+}
+
+// GetVideoInfo returns the metadata of the video at url, reusing what was
+// fetched for the same canonical URL within the last few minutes
+func (d *Downloader) GetVideoInfo(ctx context.Context, url string) (*VideoInfo, error) {
+	if info, ok := d.infoCache.get(d.CanonicalURL(url)); ok {
+		slog.Debug("Using cached video info", "url", url, "id", info.ID)
+		return info, nil
+	}
+	return d.RefreshVideoInfo(ctx, url)
+}
+
+// SaveVideoInfo atomically writes the full yt-dlp metadata of info to
+// InfoFile in dir
+func (d *Downloader) SaveVideoInfo(dir string, info *VideoInfo) error {
+	var v any = info
+	if len(info.raw) > 0 {
+		v = json.RawMessage(info.raw)
+	}
+	if err := writeJSONFile(filepath.Join(dir, InfoFile), v, "video info"); err != nil {
+		return fmt.Errorf("failed to save video info: %w", err)
+	}
+	return nil
+}
+
+// LoadVideoInfo reads the metadata saved by SaveVideoInfo for the video at
+// url. A missing file yields an error os.IsNotExist reports.
+func (d *Downloader) LoadVideoInfo(dir string, url string) (*VideoInfo, error) {
+	data, err := os.ReadFile(filepath.Join(dir, InfoFile))
+	if err != nil {
+		return nil, err
+	}
+	var info VideoInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", InfoFile, err)
+	}
+	info.raw = data
+	d.normalizeVideoInfo(url, &info)
+	return &info, nil
+}
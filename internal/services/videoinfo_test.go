@@ -0,0 +1,110 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfoCacheExpiresEntries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newInfoCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.put("a", &VideoInfo{ID: "a"})
+	if info, ok := c.get("a"); !ok || info.ID != "a" {
+		t.Fatalf("get(a) = %v, %v", info, ok)
+	}
+	info, _ := c.get("a")
+	info.ID = "changed"
+	if cached, _ := c.get("a"); cached.ID != "a" {
+		t.Error("changing a returned entry changed the cache")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("get() returned an expired entry")
+	}
+
+	for i := range maxInfoCacheEntries + 1 {
+		now = now.Add(time.Second)
+		c.put(string(rune('A'+i)), &VideoInfo{})
+	}
+	if len(c.entries) != maxInfoCacheEntries {
+		t.Errorf("cache holds %d entries, want %d", len(c.entries), maxInfoCacheEntries)
+	}
+	if _, ok := c.get("A"); ok {
+		t.Error("the oldest entry was not evicted")
+	}
+}
+
+func TestVideoInfoIsFetchedOnceAndSaved(t *testing.T) {
+	listing, argsLog := fakeYtDlp(t)
+	d := NewDownloader(nil)
+
+	raw := `{"id": "dQw4w9WgXcQ", "title": "Talk", "uploader": "Gophers", "duration": 212, "formats": [{"format_id": "18"}]}`
+	if err := os.WriteFile(listing, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	calls := func() int {
+		data, _ := os.ReadFile(argsLog)
+		return strings.Count(string(data), "--dump-json")
+	}
+
+	ctx := context.Background()
+	if _, err := d.GetVideoInfo(ctx, "https://youtu.be/dQw4w9WgXcQ?si=abc"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := d.GetVideoInfo(ctx, "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls() != 1 {
+		t.Errorf("yt-dlp ran %d times for links to the same video, want 1", calls())
+	}
+	if info.Channel != "Gophers" {
+		t.Errorf("cached info was not normalized: channel %q", info.Channel)
+	}
+	if _, err := d.RefreshVideoInfo(ctx, "https://www.youtube.com/watch?v=dQw4w9WgXcQ"); err != nil {
+		t.Fatal(err)
+	}
+	if calls() != 2 {
+		t.Errorf("RefreshVideoInfo() did not run yt-dlp")
+	}
+
+	dir := t.TempDir()
+	if err := d.SaveVideoInfo(dir, info); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(filepath.Join(dir, InfoFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(saved, &fields); err != nil || fields["formats"] == nil {
+		t.Errorf("%s does not hold the full yt-dlp output: %s", InfoFile, saved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, InfoFile+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+	loaded, err := d.LoadVideoInfo(dir, "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ID != info.ID || loaded.Title != info.Title || loaded.Channel != "Gophers" || loaded.Duration != 212 {
+		t.Errorf("LoadVideoInfo() = %+v, want %+v", loaded, info)
+	}
+	if calls() != 2 {
+		t.Error("LoadVideoInfo() ran yt-dlp")
+	}
+
+	if _, err := d.LoadVideoInfo(t.TempDir(), "https://www.youtube.com/watch?v=dQw4w9WgXcQ"); !os.IsNotExist(err) {
+		t.Errorf("LoadVideoInfo(empty dir) error = %v, want not exist", err)
+	}
+}
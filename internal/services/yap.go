@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -22,8 +23,9 @@ func NewYapRunner(storage *Storage) *YapRunner {
 	}
 }
 
-// Transcribe uses yap to transcribe audio to SRT
-func (y *YapRunner) Transcribe(audioPath string, outputDir string, language string) error {
+// Transcribe uses yap to transcribe audio to SRT. Cancelling ctx kills yap.
+// Progress percentages printed by yap are passed to onProgress.
+func (y *YapRunner) Transcribe(ctx context.Context, audioPath string, outputDir string, language string, onProgress ProgressFunc) error {
 	// Map language codes to yap locale format
 	locale := y.mapLanguageToLocale(language)
 	slog.Info("Starting transcription with yap",
@@ -34,7 +36,10 @@ func (y *YapRunner) Transcribe(audioPath string, outputDir string, language stri
 	// yap cannot download missing speech models itself (it fails with
 	// CancellationError), so install them up front. A failure here is logged
 	// but not fatal: the model may already be present even if the check fails.
-	if err := y.speechAssets.EnsureInstalled(context.Background(), locale); err != nil {
+	if err := y.speechAssets.EnsureInstalled(ctx, locale); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		slog.Warn("Speech model preinstall failed, continuing with yap", "locale", locale, "error", err)
 		if logErr := y.storage.SaveLog(outputDir, "asr", fmt.Sprintf("Speech model preinstall failed: %v", err)); logErr != nil {
 			slog.Warn("save transcription log", "error", logErr)
@@ -45,7 +50,7 @@ func (y *YapRunner) Transcribe(audioPath string, outputDir string, language stri
 	outputFile := filepath.Join(outputDir, fmt.Sprintf("subs_%s.srt", language))
 
 	// Build yap command
-	cmd := exec.Command("yap", "transcribe",
+	cmd := commandContext(ctx, "yap", "transcribe",
 		audioPath,
 		"--srt",
 		"--locale", locale,
@@ -54,7 +59,19 @@ func (y *YapRunner) Transcribe(audioPath string, outputDir string, language stri
 
 	// Execute command
 	slog.Debug("Running yap transcribe command", "cmd", cmd.String())
-	output, err := cmd.CombinedOutput()
+	output, err := runWithLines(cmd, func(line string) bool {
+		update, ok := parseYapProgress(line)
+		if ok && onProgress != nil {
+			onProgress(update)
+		}
+		return ok
+	})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%w: yap not found: %v", ErrDependencyMissing, err)
+	}
 	if err != nil {
 		slog.Error("Yap transcription failed",
 			"error", err,
@@ -69,15 +86,15 @@ func (y *YapRunner) Transcribe(audioPath string, outputDir string, language stri
 			detail = detail[:300] + "…"
 		}
 		if detail == "" {
-			return fmt.Errorf("transcription failed: %v", err)
+			return fmt.Errorf("%w: %v", ErrASRFailed, err)
 		}
-		return fmt.Errorf("transcription failed: %v: %s", err, detail)
+		return fmt.Errorf("%w: %v: %s", ErrASRFailed, err, detail)
 	}
 
 	// Check if output file was created
 	if _, err := os.Stat(outputFile); os.IsNotExist(err) {
 		slog.Error("Transcription output file not created", "outputFile", outputFile)
-		return fmt.Errorf("transcription completed but no output file created")
+		return fmt.Errorf("%w: no output file created", ErrASRFailed)
 	}
 
 	slog.Info("Transcription completed successfully",
@@ -92,6 +109,15 @@ func (y *YapRunner) Transcribe(audioPath string, outputDir string, language stri
 	return nil
 }
 
+// TranscribeParams returns the parameters that determine the transcript
+// produced for language
+func (y *YapRunner) TranscribeParams(language string) map[string]string {
+	return map[string]string{
+		"language": language,
+		"locale":   y.mapLanguageToLocale(language),
+	}
+}
+
 // mapLanguageToLocale maps language codes to yap locale format
 func (y *YapRunner) mapLanguageToLocale(lang string) string {
 	// Map common language codes to yap locale format
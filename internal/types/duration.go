@@ -0,0 +1,38 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatDuration formats a length in seconds as "mm:ss", or "h:mm:ss" from
+// one hour on
+func FormatDuration(seconds int) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// ParseDuration reads a length written as "ss", "mm:ss" or "h:mm:ss" back
+// into seconds. Minutes past 59 are accepted, as older versions wrote an
+// hour and a quarter as "75:00".
+func ParseDuration(s string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+	seconds := 0
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, true
+}
@@ -0,0 +1,49 @@
+package types
+
+import "testing"
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{0, "00:00"},
+		{59, "00:59"},
+		{307, "05:07"},
+		{3599, "59:59"},
+		{3600, "1:00:00"},
+		{4500, "1:15:00"},
+		{36061, "10:01:01"},
+		{-5, "00:00"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.seconds); got != tt.want {
+			t.Errorf("FormatDuration(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"05:07", 307, true},
+		{"1:15:00", 4500, true},
+		{"75:00", 4500, true},
+		{"42", 42, true},
+		{"00:00", 0, true},
+		{"1:60", 0, false},
+		{"1:60:00", 0, false},
+		{"1:2:3:4", 0, false},
+		{"", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseDuration(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseDuration(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
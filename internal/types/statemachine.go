@@ -0,0 +1,149 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is wrapped by every error returned for a status change
+// the task state machine does not allow
+var ErrInvalidTransition = errors.New("invalid task status transition")
+
+// transitions lists, for each status, the statuses a task may move to next.
+// Staying in the same status (e.g. to report progress) is always allowed.
+// Tasks return to queued between stages while they wait for a worker. Any
+// unfinished status may fail or be cancelled; failed and cancelled tasks are
+// retried through pending, and finished or failed tasks may re-enter a single
+// stage when it is run manually.
+var transitions = map[TaskStatus][]TaskStatus{
+	TaskStatusPending: {
+		TaskStatusQueued, TaskStatusDownloading,
+		TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusQueued: {
+		TaskStatusDownloading, TaskStatusTranscribing, TaskStatusSummarizing,
+		TaskStatusPaused, TaskStatusDone, TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusDownloading: {
+		TaskStatusQueued, TaskStatusTranscribing,
+		TaskStatusPaused, TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusTranscribing: {
+		TaskStatusQueued, TaskStatusTranslating, TaskStatusSummarizing,
+		TaskStatusPaused, TaskStatusDone, TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusTranslating: {
+		TaskStatusQueued, TaskStatusSummarizing,
+		TaskStatusPaused, TaskStatusDone, TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusSummarizing: {
+		TaskStatusQueued,
+		TaskStatusPaused, TaskStatusDone, TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusPaused: {
+		TaskStatusQueued, TaskStatusFailed, TaskStatusCancelled,
+	},
+	TaskStatusDone: {
+		TaskStatusPending, TaskStatusQueued,
+		TaskStatusDownloading, TaskStatusTranscribing, TaskStatusSummarizing,
+	},
+	TaskStatusFailed: {
+		TaskStatusPending, TaskStatusQueued,
+		TaskStatusDownloading, TaskStatusTranscribing, TaskStatusSummarizing,
+	},
+	TaskStatusCancelled: {
+		TaskStatusPending,
+	},
+}
+
+// guards hold the preconditions for entering a status from a different one
+var guards = map[TaskStatus]func(task *Task) error{
+	TaskStatusTranscribing: func(task *Task) error {
+		if task.Progress < ProgressAudioExtracted {
+			return errors.New("download stage must complete before transcription")
+		}
+		return nil
+	},
+	TaskStatusSummarizing: func(task *Task) error {
+		if task.Progress < ProgressTranscribeComplete {
+			return errors.New("transcription stage must complete before summarization")
+		}
+		return nil
+	},
+}
+
+// Statuses returns every known task status
+func Statuses() []TaskStatus {
+	return []TaskStatus{
+		TaskStatusPending, TaskStatusQueued, TaskStatusDownloading,
+		TaskStatusTranscribing, TaskStatusTranslating, TaskStatusSummarizing,
+		TaskStatusPaused, TaskStatusDone, TaskStatusFailed, TaskStatusCancelled,
+	}
+}
+
+// CanTransition reports whether the state machine allows moving from one
+// status to another, ignoring guards
+func CanTransition(from, to TaskStatus) bool {
+	if from == to {
+		_, known := transitions[from]
+		return known
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition checks that task may move to the given status: the
+// transition must be legal and the guard of the target status, if any, must
+// pass. The returned error wraps ErrInvalidTransition.
+func ValidateTransition(task *Task, to TaskStatus) error {
+	from := task.Status
+	if _, known := transitions[to]; !known {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, to)
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: cannot move task from %s to %s", ErrInvalidTransition, from, to)
+	}
+	if from == to {
+		return nil
+	}
+	if guard, ok := guards[to]; ok {
+		if err := guard(task); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+		}
+	}
+	return nil
+}
+
+// StageForProgress returns the pipeline stage a task with the given overall
+// progress has to run next
+func StageForProgress(progress int) TaskStatus {
+	switch {
+	case progress < ProgressAudioExtracted:
+		return TaskStatusDownloading
+	case progress < ProgressTranscribeComplete:
+		return TaskStatusTranscribing
+	default:
+		return TaskStatusSummarizing
+	}
+}
+
+// IsTerminal reports whether the status ends processing
+func (s TaskStatus) IsTerminal() bool {
+	return s == TaskStatusDone || s == TaskStatusFailed || s == TaskStatusCancelled
+}
+
+// IsActive reports whether a task in this status is waiting for or running a
+// pipeline stage
+func (s TaskStatus) IsActive() bool {
+	switch s {
+	case TaskStatusPending, TaskStatusQueued, TaskStatusDownloading,
+		TaskStatusTranscribing, TaskStatusTranslating, TaskStatusSummarizing:
+		return true
+	default:
+		return false
+	}
+}
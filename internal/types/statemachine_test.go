@@ -0,0 +1,148 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to TaskStatus
+		want     bool
+	}{
+		{TaskStatusPending, TaskStatusQueued, true},
+		{TaskStatusPending, TaskStatusDownloading, true},
+		{TaskStatusPending, TaskStatusTranscribing, false},
+		{TaskStatusPending, TaskStatusDone, false},
+		{TaskStatusQueued, TaskStatusDownloading, true},
+		{TaskStatusQueued, TaskStatusPaused, true},
+		{TaskStatusDownloading, TaskStatusTranscribing, true},
+		{TaskStatusDownloading, TaskStatusSummarizing, false},
+		{TaskStatusDownloading, TaskStatusDone, false},
+		{TaskStatusDownloading, TaskStatusQueued, true},
+		{TaskStatusTranscribing, TaskStatusSummarizing, true},
+		{TaskStatusTranscribing, TaskStatusDownloading, false},
+		{TaskStatusSummarizing, TaskStatusDone, true},
+		{TaskStatusSummarizing, TaskStatusTranscribing, false},
+		{TaskStatusPaused, TaskStatusQueued, true},
+		{TaskStatusPaused, TaskStatusDownloading, false},
+		{TaskStatusDone, TaskStatusPending, true},
+		{TaskStatusDone, TaskStatusSummarizing, true},
+		{TaskStatusDone, TaskStatusFailed, false},
+		{TaskStatusDone, TaskStatusCancelled, false},
+		{TaskStatusFailed, TaskStatusPending, true},
+		{TaskStatusFailed, TaskStatusCancelled, false},
+		{TaskStatusCancelled, TaskStatusPending, true},
+		{TaskStatusCancelled, TaskStatusDownloading, false},
+		{TaskStatusCancelled, TaskStatusQueued, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSelfTransitionsAllowed(t *testing.T) {
+	for _, status := range Statuses() {
+		if !CanTransition(status, status) {
+			t.Errorf("CanTransition(%s, %s) = false, want true", status, status)
+		}
+	}
+	if CanTransition("bogus", "bogus") {
+		t.Error("unknown status must not transition to itself")
+	}
+}
+
+func TestEveryStatusHasTransitions(t *testing.T) {
+	for _, status := range Statuses() {
+		if _, ok := transitions[status]; !ok {
+			t.Errorf("status %s is missing from the transition table", status)
+		}
+	}
+	for from, targets := range transitions {
+		for _, to := range targets {
+			if _, ok := transitions[to]; !ok {
+				t.Errorf("%s -> %s targets a status missing from the table", from, to)
+			}
+		}
+	}
+}
+
+func TestUnfinishedStatusesCanFailOrBeCancelled(t *testing.T) {
+	for _, status := range Statuses() {
+		if status.IsTerminal() {
+			continue
+		}
+		for _, to := range []TaskStatus{TaskStatusFailed, TaskStatusCancelled} {
+			if !CanTransition(status, to) {
+				t.Errorf("CanTransition(%s, %s) = false, want true", status, to)
+			}
+		}
+	}
+}
+
+func TestValidateTransitionGuards(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   TaskStatus
+		progress int
+		to       TaskStatus
+		wantErr  bool
+	}{
+		{"transcribe after audio extracted", TaskStatusDownloading, ProgressAudioExtracted, TaskStatusTranscribing, false},
+		{"transcribe before audio extracted", TaskStatusDownloading, ProgressVideoDownloaded, TaskStatusTranscribing, true},
+		{"transcribe failed download", TaskStatusFailed, ProgressMetadataFetched, TaskStatusTranscribing, true},
+		{"retry failed transcription", TaskStatusFailed, ProgressTranscribeStart, TaskStatusTranscribing, false},
+		{"summarize after transcript", TaskStatusTranscribing, ProgressTranscribeComplete, TaskStatusSummarizing, false},
+		{"summarize mid transcription", TaskStatusTranscribing, ProgressTranscribeStart, TaskStatusSummarizing, true},
+		{"summarize queued task", TaskStatusQueued, ProgressTranscribeComplete, TaskStatusSummarizing, false},
+		{"resummarize finished task", TaskStatusDone, ProgressTaskComplete, TaskStatusSummarizing, false},
+		{"guards skipped for self transition", TaskStatusTranscribing, ProgressTranscribeStart, TaskStatusTranscribing, false},
+		{"download has no guard", TaskStatusQueued, 0, TaskStatusDownloading, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Status: tt.status, Progress: tt.progress}
+			err := ValidateTransition(task, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("error %v does not wrap ErrInvalidTransition", err)
+			}
+		})
+	}
+}
+
+func TestValidateTransitionErrors(t *testing.T) {
+	err := ValidateTransition(&Task{Status: TaskStatusDone}, TaskStatusCancelled)
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if want := "invalid task status transition: cannot move task from done to cancelled"; err.Error() != want {
+		t.Fatalf("error = %q, want %q", err.Error(), want)
+	}
+
+	err = ValidateTransition(&Task{Status: TaskStatusPending}, "archived")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition for unknown status, got %v", err)
+	}
+}
+
+func TestStatusPredicates(t *testing.T) {
+	terminal := map[TaskStatus]bool{TaskStatusDone: true, TaskStatusFailed: true, TaskStatusCancelled: true}
+	for _, status := range Statuses() {
+		if got := status.IsTerminal(); got != terminal[status] {
+			t.Errorf("%s.IsTerminal() = %v, want %v", status, got, terminal[status])
+		}
+		if status.IsTerminal() && status.IsActive() {
+			t.Errorf("%s is both terminal and active", status)
+		}
+	}
+	if TaskStatusPaused.IsActive() {
+		t.Error("paused tasks must not count as active")
+	}
+}
@@ -7,14 +7,172 @@ type TaskStatus string
 
 const (
 	TaskStatusPending      TaskStatus = "pending"
+	TaskStatusQueued       TaskStatus = "queued"
 	TaskStatusDownloading  TaskStatus = "downloading"
 	TaskStatusTranscribing TaskStatus = "transcribing"
 	TaskStatusTranslating  TaskStatus = "translating"
 	TaskStatusSummarizing  TaskStatus = "summarizing"
 	TaskStatusDone         TaskStatus = "done"
 	TaskStatusFailed       TaskStatus = "failed"
+	TaskStatusPaused       TaskStatus = "paused"
+	TaskStatusCancelled    TaskStatus = "cancelled"
 )
 
+// Overall task progress reached at each point of the pipeline
+const (
+	ProgressDownloadStart      = 10
+	ProgressMetadataFetched    = 30
+	ProgressVideoDownloaded    = 45
+	ProgressAudioExtracted     = 60
+	ProgressTranscribeStart    = 60
+	ProgressTranscribeComplete = 80
+	ProgressSummarizeStart     = 85
+	ProgressSummarizeComplete  = 95
+	ProgressTaskComplete       = 100
+)
+
+// ErrorCode classifies why a task failed
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown            ErrorCode = "unknown"
+	ErrorCodePrivate            ErrorCode = "private"
+	ErrorCodeGeoBlocked         ErrorCode = "geo_blocked"
+	ErrorCodeRemoved            ErrorCode = "removed"
+	ErrorCodeForbidden          ErrorCode = "forbidden"
+	ErrorCodeLoginRequired      ErrorCode = "login_required"
+	ErrorCodeRateLimited        ErrorCode = "rate_limited"
+	ErrorCodeNetworkTimeout     ErrorCode = "network_timeout"
+	ErrorCodeNetworkError       ErrorCode = "network_error"
+	ErrorCodeServiceUnavailable ErrorCode = "service_unavailable"
+	ErrorCodeDependencyMissing  ErrorCode = "dependency_missing"
+	ErrorCodeFileTooLarge       ErrorCode = "file_too_large"
+	ErrorCodeASRFailed          ErrorCode = "asr_failed"
+	ErrorCodeLLMQuota           ErrorCode = "llm_quota"
+	ErrorCodeInterrupted        ErrorCode = "interrupted"
+)
+
+// DownloadMode selects what the download stage fetches
+type DownloadMode string
+
+const (
+	// DownloadModeVideo downloads the video with audio for playback
+	DownloadModeVideo DownloadMode = "video"
+	// DownloadModeAudio downloads only the best audio track as source.m4a
+	DownloadModeAudio DownloadMode = "audio"
+)
+
+// FormatPreset is a named choice of the format the download stage fetches
+type FormatPreset struct {
+	Name string `json:"name"`
+	// Format is the yt-dlp format selector
+	Format string `json:"format"`
+	// FallbackFormat, if set, is tried in the WebM container when Format
+	// cannot be downloaded into Container
+	FallbackFormat string `json:"fallbackFormat,omitempty"`
+	// Container is the file the streams are merged into: mp4 (default) or webm
+	Container string `json:"container,omitempty"`
+	// MaxFilesize makes downloads larger than this fail, in yt-dlp syntax
+	// such as 500M
+	MaxFilesize string `json:"maxFilesize,omitempty"`
+	// AudioOnly fetches only the audio track, as DownloadModeAudio does
+	AudioOnly bool `json:"audioOnly,omitempty"`
+}
+
+// Mode returns the download mode the preset implies
+func (p FormatPreset) Mode() DownloadMode {
+	if p.AudioOnly {
+		return DownloadModeAudio
+	}
+	return DownloadModeVideo
+}
+
+// PlatformAuth holds the cookies yt-dlp signs in to a platform with. At most
+// one of the fields is set.
+type PlatformAuth struct {
+	// CookiesFile is a Netscape-format cookies file kept in the app's config
+	// directory
+	CookiesFile string `json:"cookiesFile,omitempty"`
+	// CookiesFromBrowser names the browser, and optionally the profile, to
+	// read cookies from, in yt-dlp's BROWSER[+KEYRING][:PROFILE] syntax
+	CookiesFromBrowser string `json:"cookiesFromBrowser,omitempty"`
+}
+
+// NetworkSettings controls how yt-dlp and API clients reach the network
+type NetworkSettings struct {
+	// Proxy is an http, https or socks5 proxy URL; empty connects directly
+	// or through the proxy set in the environment
+	Proxy string `json:"proxy,omitempty"`
+	// RateLimit caps the download speed in bytes per second, in yt-dlp
+	// syntax such as 2M
+	RateLimit string `json:"rateLimit,omitempty"`
+	// Retries is how often yt-dlp retries a failed request or fragment
+	// (0 = yt-dlp's default)
+	Retries int `json:"retries,omitempty"`
+	// SleepRequests is the number of seconds yt-dlp waits between the
+	// requests it makes to extract a video
+	SleepRequests float64 `json:"sleepRequests,omitempty"`
+	// SleepInterval is the number of seconds yt-dlp waits before each download
+	SleepInterval float64 `json:"sleepInterval,omitempty"`
+}
+
+// DownloadedFormat records what the download stage fetched for a task
+type DownloadedFormat struct {
+	// Preset is the name of the format preset used
+	Preset string `json:"preset"`
+	// Format is the yt-dlp format selector that produced the file
+	Format string `json:"format"`
+	// File is the downloaded file, relative to the task's WorkDir
+	File string `json:"file"`
+	// Resolution of the video stream; zero for audio-only downloads
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+}
+
+// TranscriptSource tells where a task's transcript came from
+type TranscriptSource string
+
+const (
+	// TranscriptSourceOfficial is a subtitle track uploaded with the video
+	TranscriptSourceOfficial TranscriptSource = "official"
+	// TranscriptSourceAuto is a caption track generated by the platform
+	TranscriptSourceAuto TranscriptSource = "auto"
+	// TranscriptSourceASR is a transcript produced locally by yap
+	TranscriptSourceASR TranscriptSource = "asr"
+)
+
+// Captions describes the caption track downloaded alongside a video
+type Captions struct {
+	Lang   string           `json:"lang"` // track language as listed by the platform
+	Source TranscriptSource `json:"source"`
+}
+
+// StageOutcome describes how a pipeline stage attempt ended
+type StageOutcome string
+
+const (
+	StageOutcomeRunning     StageOutcome = "running"
+	StageOutcomeSucceeded   StageOutcome = "succeeded"
+	StageOutcomeSkipped     StageOutcome = "skipped"
+	StageOutcomeFailed      StageOutcome = "failed"
+	StageOutcomeCancelled   StageOutcome = "cancelled"
+	StageOutcomePaused      StageOutcome = "paused"
+	StageOutcomeInterrupted StageOutcome = "interrupted"
+)
+
+// StageRecord is one attempt at a pipeline stage in a task's timeline
+type StageRecord struct {
+	Stage        TaskStatus        `json:"stage"`
+	Attempt      int               `json:"attempt"`
+	StartedAt    time.Time         `json:"startedAt"`
+	EndedAt      *time.Time        `json:"endedAt,omitempty"`
+	DurationMs   int64             `json:"durationMs"`
+	Outcome      StageOutcome      `json:"outcome"`
+	Error        string            `json:"error,omitempty"`
+	ErrorCode    ErrorCode         `json:"errorCode,omitempty"`
+	ToolVersions map[string]string `json:"toolVersions,omitempty"`
+}
+
 // Task represents a video processing task
 type Task struct {
 	ID          string     `json:"id"`
@@ -23,31 +181,130 @@ type Task struct {
 	VideoID     string     `json:"videoId"`
 	Title       string     `json:"title"`
 	Channel     string     `json:"channel"`
-	Duration    string     `json:"duration"`
+	Duration    string     `json:"duration"` // DurationSeconds as mm:ss or h:mm:ss
 	Thumbnail   string     `json:"thumbnail"`
 	SourceLang  string     `json:"sourceLang"`
 	Status      TaskStatus `json:"status"`
 	Progress    int        `json:"progress"`
 	Error       string     `json:"error,omitempty"`
+	ErrorCode   ErrorCode  `json:"errorCode,omitempty"`
 	WorkDir     string     `json:"workDir"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
 	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// DurationSeconds is the length of the video
+	DurationSeconds int `json:"durationSeconds,omitempty"`
+	// Metadata is everything known about the video. Tasks created from a
+	// listing only have what the listing shows until the download stage
+	// reads the video's own metadata.
+	Metadata *VideoMetadata `json:"metadata,omitempty"`
+	// RecoveryAttempts counts how often the task was resumed after the app
+	// quit while it was running
+	RecoveryAttempts int `json:"recoveryAttempts,omitempty"`
+	// Transfer holds live statistics of the running download, if any
+	Transfer *TransferStats `json:"transfer,omitempty"`
+	// PausedStage is the stage a paused task resumes from
+	PausedStage TaskStatus `json:"pausedStage,omitempty"`
+	// Captions is the caption track saved as captions.vtt, if any
+	Captions *Captions `json:"captions,omitempty"`
+	// TranscriptSource tells whether the transcript came from captions or ASR
+	TranscriptSource TranscriptSource `json:"transcriptSource,omitempty"`
+	// DownloadMode overrides the download mode from settings for this task
+	DownloadMode DownloadMode `json:"downloadMode,omitempty"`
+	// FormatPreset names the format preset chosen for this task; empty means
+	// the default preset from settings
+	FormatPreset string `json:"formatPreset,omitempty"`
+	// Format records what the last download fetched
+	Format *DownloadedFormat `json:"format,omitempty"`
+	// ForceASR makes the task transcribe with yap even when captions exist
+	ForceASR bool `json:"forceAsr,omitempty"`
+	// SourceFile is the imported media of a local task, relative to WorkDir
+	SourceFile string `json:"sourceFile,omitempty"`
+	// CollectionID is shared by the tasks created from one playlist, channel
+	// or series
+	CollectionID string `json:"collectionId,omitempty"`
+	// Stages is the history of stage attempts, oldest first
+	Stages []StageRecord `json:"stages,omitempty"`
+}
+
+// TransferStats describes the progress of a running download
+type TransferStats struct {
+	DownloadedBytes int64   `json:"downloadedBytes"`
+	TotalBytes      int64   `json:"totalBytes"`
+	Speed           float64 `json:"speed"` // bytes per second
+	ETA             int     `json:"eta"`   // seconds
+}
+
+// RerunOptions adjusts a task before part of its pipeline is run again
+type RerunOptions struct {
+	// SourceLang, if set, replaces the language the video is transcribed in
+	SourceLang string `json:"sourceLang,omitempty"`
+	// ForceASR, if set, replaces the task's choice between captions and ASR
+	ForceASR *bool `json:"forceAsr,omitempty"`
+	// DownloadMode, if set, replaces the task's download mode
+	DownloadMode DownloadMode `json:"downloadMode,omitempty"`
+	// FormatPreset, if set, replaces the task's format preset
+	FormatPreset string `json:"formatPreset,omitempty"`
 }
 
 // VideoMetadata contains information about a video from various platforms
 type VideoMetadata struct {
-	ID          string `json:"id"`
-	Platform    string `json:"platform"`
-	Title       string `json:"title"`
-	Channel     string `json:"channel"`
-	ChannelID   string `json:"channelId"`
-	Duration    int    `json:"duration"` // in seconds
-	PublishedAt string `json:"publishedAt"`
-	Thumbnail   string `json:"thumbnail"`
-	ViewCount   int64  `json:"viewCount"`
-	LikeCount   int64  `json:"likeCount"`
-	Description string `json:"description"`
+	ID          string   `json:"id"`
+	Platform    string   `json:"platform"`
+	Title       string   `json:"title"`
+	Channel     string   `json:"channel"`
+	ChannelID   string   `json:"channelId"`
+	Duration    int      `json:"duration"` // in seconds
+	PublishedAt string   `json:"publishedAt"`
+	Thumbnail   string   `json:"thumbnail"`
+	ViewCount   int64    `json:"viewCount"`
+	LikeCount   int64    `json:"likeCount"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Chapter is a titled section of a video; times are in seconds
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Collection is a playlist, channel tab or series listing several videos
+type Collection struct {
+	ID       string            `json:"id"`
+	Platform string            `json:"platform"`
+	URL      string            `json:"url"`
+	Title    string            `json:"title"`
+	Channel  string            `json:"channel"`
+	Entries  []CollectionEntry `json:"entries"`
+}
+
+// CollectionEntry is a video listed in a collection
+type CollectionEntry struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Channel   string `json:"channel"`
+	Duration  int    `json:"duration"` // in seconds, 0 if unknown
+	Thumbnail string `json:"thumbnail"`
+}
+
+// Subscription follows a channel so that its new uploads are transcribed and
+// summarized without the user pasting their URLs
+type Subscription struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url"`
+	Platform      string     `json:"platform"`
+	Channel       string     `json:"channel"`
+	ChannelID     string     `json:"channelId"`
+	Enabled       bool       `json:"enabled"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastCheckedAt *time.Time `json:"lastCheckedAt,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	// SeenVideoIDs are the most recent uploads already handled, so that only
+	// videos published after subscribing are picked up
+	SeenVideoIDs []string `json:"seenVideoIds,omitempty"`
 }
 
 // Subtitle represents a subtitle entry
@@ -84,9 +341,10 @@ type QAPair struct {
 
 // DependencyStatus shows which dependencies are installed
 type DependencyStatus struct {
-	YtDlp  bool `json:"ytdlp"`
-	FFmpeg bool `json:"ffmpeg"`
-	Yap    bool `json:"yap"`
+	YtDlp   bool `json:"ytdlp"`
+	FFmpeg  bool `json:"ffmpeg"`
+	FFprobe bool `json:"ffprobe"`
+	Yap     bool `json:"yap"`
 }
 
 // Settings represents user configuration
@@ -100,4 +358,20 @@ type Settings struct {
 	Temperature          float64           `json:"temperature"`
 	MaxTokens            int               `json:"maxTokens"`
 	ChannelLanguagePrefs map[string]string `json:"channelLanguagePrefs"`
+	// Number of tasks allowed to run each stage concurrently (0 = default)
+	MaxConcurrentDownloads      int `json:"maxConcurrentDownloads"`
+	MaxConcurrentTranscriptions int `json:"maxConcurrentTranscriptions"`
+	MaxConcurrentSummaries      int `json:"maxConcurrentSummaries"`
+	// DownloadMode is what tasks download unless they override it
+	DownloadMode DownloadMode `json:"downloadMode"`
+	// FormatPresets are the formats tasks can be downloaded in, and
+	// DefaultFormatPreset names the one used unless a task picks another
+	FormatPresets       []FormatPreset `json:"formatPresets"`
+	DefaultFormatPreset string         `json:"defaultFormatPreset"`
+	// Network holds the proxy, rate limit and retry settings
+	Network NetworkSettings `json:"network"`
+	// Auth holds the cookies used for each platform, keyed by platform name
+	Auth map[string]PlatformAuth `json:"auth,omitempty"`
+	// Minutes between two checks of the subscribed channels (0 = default)
+	SubscriptionIntervalMinutes int `json:"subscriptionIntervalMinutes"`
 }
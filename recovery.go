@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"transcube-webapp/internal/services"
+	"transcube-webapp/internal/types"
+)
+
+// maxRecoveryAttempts bounds how often a task is resumed after the app quit
+// mid-stage, so a task that crashes the app cannot do so on every launch.
+const maxRecoveryAttempts = 3
+
+const reasonMissingWorkDir = "work directory is missing"
+
+// recoveryPlan describes what to do with a task found unfinished on startup
+type recoveryPlan struct {
+	resumeFrom types.TaskStatus
+	progress   int
+	finished   bool
+	reason     string
+}
+
+// planRecovery decides where an unfinished task should resume, giving up on
+// tasks that were already resumed maxRecoveryAttempts times
+func planRecovery(task *types.Task) recoveryPlan {
+	plan := planFromArtifacts(task)
+	if plan.reason == "" && task.RecoveryAttempts > maxRecoveryAttempts {
+		plan = recoveryPlan{reason: fmt.Sprintf("the app quit during processing %d times", task.RecoveryAttempts)}
+	}
+	return plan
+}
+
+// planFromArtifacts checks which stage artifacts exist on disk. An artifact
+// only counts when the persisted progress shows its stage completed, since a
+// file written by an interrupted ffmpeg or yap run may be truncated.
+func planFromArtifacts(task *types.Task) recoveryPlan {
+	if task.WorkDir == "" {
+		return recoveryPlan{reason: reasonMissingWorkDir}
+	}
+	if info, err := os.Stat(task.WorkDir); err != nil || !info.IsDir() {
+		return recoveryPlan{reason: reasonMissingWorkDir}
+	}
+
+	hasVideo := fileExists(filepath.Join(task.WorkDir, "video.mp4")) ||
+		fileExists(filepath.Join(task.WorkDir, "video.webm")) ||
+		fileExists(filepath.Join(task.WorkDir, services.AudioOnlyFile))
+	hasAudio := task.Progress >= types.ProgressAudioExtracted &&
+		fileExists(filepath.Join(task.WorkDir, "audio.aac"))
+	hasTranscript := task.Progress >= types.ProgressTranscribeComplete &&
+		fileExists(filepath.Join(task.WorkDir, fmt.Sprintf("subs_%s.srt", task.SourceLang)))
+	hasSummary := task.Progress >= types.ProgressSummarizeComplete &&
+		fileExists(filepath.Join(task.WorkDir, "summary_structured.json"))
+
+	switch {
+	case hasTranscript && hasSummary:
+		return recoveryPlan{finished: true}
+	case hasTranscript:
+		return recoveryPlan{resumeFrom: types.TaskStatusSummarizing, progress: types.ProgressTranscribeComplete}
+	case hasAudio:
+		return recoveryPlan{resumeFrom: types.TaskStatusTranscribing, progress: types.ProgressAudioExtracted}
+	case task.URL == "" && !hasVideo:
+		return recoveryPlan{reason: "source URL is missing and no media was downloaded"}
+	default:
+		return recoveryPlan{resumeFrom: types.TaskStatusDownloading}
+	}
+}
+
+// recoverTasks runs once on startup. Tasks persisted in an unfinished state
+// were orphaned when the app quit; each one is resumed from its last
+// completed stage in queue order, or marked failed when it cannot be resumed.
+func (a *App) recoverTasks() {
+	order, err := a.taskManager.LoadQueue()
+	if err != nil {
+		a.logger.Warn("Failed to load task queue", "error", err)
+	}
+
+	tasks, err := a.storage.GetAllTasks()
+	if err != nil {
+		a.logger.Error("Failed to load tasks for recovery", "error", err)
+		return
+	}
+
+	// Tasks downloaded before chapters were saved get their chapters.json
+	for _, task := range tasks {
+		a.ensureChapters(task)
+	}
+
+	position := make(map[string]int, len(order))
+	for i, id := range order {
+		position[id] = i
+	}
+
+	var orphaned []*types.Task
+	for _, task := range tasks {
+		if task.Status.IsActive() {
+			orphaned = append(orphaned, task)
+		}
+	}
+	// Previously queued tasks keep their order; the rest follow by age
+	sort.SliceStable(orphaned, func(i, j int) bool {
+		pi, iQueued := position[orphaned[i].ID]
+		pj, jQueued := position[orphaned[j].ID]
+		if iQueued != jQueued {
+			return iQueued
+		}
+		if iQueued {
+			return pi < pj
+		}
+		return orphaned[i].CreatedAt.Before(orphaned[j].CreatedAt)
+	})
+
+	recovered := make(map[string]bool, len(orphaned))
+	for _, task := range orphaned {
+		recovered[task.ID] = true
+		a.recoverTask(task)
+	}
+
+	for _, id := range order {
+		if !recovered[id] {
+			a.taskManager.Dequeue(id)
+		}
+	}
+}
+
+// recoverTask applies the recovery plan for a single orphaned task
+func (a *App) recoverTask(task *types.Task) {
+	interruptedStage := task.Status
+	wasRunning := task.Status != types.TaskStatusPending && task.Status != types.TaskStatusQueued
+	if wasRunning {
+		task.RecoveryAttempts++
+	}
+
+	plan := planRecovery(task)
+	if !plan.finished && plan.reason == "" {
+		task.Progress = plan.progress
+	}
+
+	if _, err := a.taskManager.UpsertTask(task); err != nil {
+		a.logger.Error("Failed to load orphaned task", "taskId", task.ID, "error", err)
+		return
+	}
+
+	switch {
+	case plan.finished:
+		a.taskManager.Dequeue(task.ID)
+		if err := a.taskManager.UpdateTaskStatus(task.ID, types.TaskStatusDone, types.ProgressTaskComplete); err != nil {
+			a.logger.Error("Failed to finalize recovered task", "taskId", task.ID, "error", err)
+			return
+		}
+		a.logger.Info("Recovered task had already finished", "taskId", task.ID)
+
+	case plan.reason != "":
+		a.taskManager.Dequeue(task.ID)
+		message := fmt.Sprintf("Interrupted while %s: %s", interruptedStage, plan.reason)
+		if err := a.taskManager.SetTaskError(task.ID, types.ErrorCodeInterrupted, message); err != nil {
+			a.logger.Error("Failed to mark interrupted task as failed", "taskId", task.ID, "error", err)
+			return
+		}
+		if plan.reason != reasonMissingWorkDir {
+			_ = a.storage.SaveLog(task.WorkDir, "recovery", message)
+		}
+		a.logger.Warn("Interrupted task cannot be resumed", "taskId", task.ID, "reason", plan.reason)
+
+	default:
+		if _, err := a.taskManager.Enqueue(task.ID); err != nil {
+			a.logger.Error("Failed to enqueue recovered task", "taskId", task.ID, "error", err)
+			return
+		}
+		if wasRunning {
+			_ = a.storage.SaveLog(task.WorkDir, "recovery",
+				fmt.Sprintf("Interrupted while %s; resuming from %s", interruptedStage, plan.resumeFrom))
+		}
+		a.logger.Info("Resuming interrupted task", "taskId", task.ID, "interrupted", interruptedStage, "resumeFrom", plan.resumeFrom)
+		go a.processTaskFrom(task.ID, plan.resumeFrom)
+	}
+}
+
+// fileExists reports whether path exists and is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
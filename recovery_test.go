@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"transcube-webapp/internal/types"
+)
+
+func TestPlanRecovery(t *testing.T) {
+	const url = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	tests := []struct {
+		name     string
+		status   types.TaskStatus
+		progress int
+		url      string
+		files    []string
+		attempts int
+		want     recoveryPlan
+	}{
+		{
+			name:   "queued before download",
+			status: types.TaskStatusQueued,
+			url:    url,
+			want:   recoveryPlan{resumeFrom: types.TaskStatusDownloading},
+		},
+		{
+			name:     "downloading with partial video",
+			status:   types.TaskStatusDownloading,
+			progress: types.ProgressDownloadStart,
+			url:      url,
+			files:    []string{"video.mp4.part"},
+			want:     recoveryPlan{resumeFrom: types.TaskStatusDownloading},
+		},
+		{
+			name:     "audio written but extraction unfinished",
+			status:   types.TaskStatusDownloading,
+			progress: types.ProgressDownloadStart,
+			url:      url,
+			files:    []string{"video.mp4", "audio.aac"},
+			want:     recoveryPlan{resumeFrom: types.TaskStatusDownloading},
+		},
+		{
+			name:     "transcribing",
+			status:   types.TaskStatusTranscribing,
+			progress: types.ProgressAudioExtracted,
+			url:      url,
+			files:    []string{"video.mp4", "audio.aac", "subs_en.srt"},
+			want:     recoveryPlan{resumeFrom: types.TaskStatusTranscribing, progress: types.ProgressAudioExtracted},
+		},
+		{
+			name:     "translating",
+			status:   types.TaskStatusTranslating,
+			progress: types.ProgressTranscribeComplete,
+			url:      url,
+			files:    []string{"audio.aac", "subs_en.srt"},
+			want:     recoveryPlan{resumeFrom: types.TaskStatusSummarizing, progress: types.ProgressTranscribeComplete},
+		},
+		{
+			name:     "summarizing with partial summary",
+			status:   types.TaskStatusSummarizing,
+			progress: types.ProgressTranscribeComplete,
+			url:      url,
+			files:    []string{"audio.aac", "subs_en.srt", "summary_structured.json"},
+			want:     recoveryPlan{resumeFrom: types.TaskStatusSummarizing, progress: types.ProgressTranscribeComplete},
+		},
+		{
+			name:     "summary saved",
+			status:   types.TaskStatusSummarizing,
+			progress: types.ProgressSummarizeComplete,
+			url:      url,
+			files:    []string{"audio.aac", "subs_en.srt", "summary_structured.json"},
+			want:     recoveryPlan{finished: true},
+		},
+		{
+			name:     "local import without media",
+			status:   types.TaskStatusDownloading,
+			progress: types.ProgressDownloadStart,
+			want:     recoveryPlan{reason: "source URL is missing and no media was downloaded"},
+		},
+		{
+			name:     "local import with media",
+			status:   types.TaskStatusDownloading,
+			progress: types.ProgressDownloadStart,
+			files:    []string{"video.mp4"},
+			want:     recoveryPlan{resumeFrom: types.TaskStatusDownloading},
+		},
+		{
+			name:     "at the attempt limit",
+			status:   types.TaskStatusTranscribing,
+			progress: types.ProgressAudioExtracted,
+			url:      url,
+			files:    []string{"audio.aac"},
+			attempts: maxRecoveryAttempts,
+			want:     recoveryPlan{resumeFrom: types.TaskStatusTranscribing, progress: types.ProgressAudioExtracted},
+		},
+		{
+			name:     "past the attempt limit",
+			status:   types.TaskStatusTranscribing,
+			progress: types.ProgressAudioExtracted,
+			url:      url,
+			files:    []string{"audio.aac"},
+			attempts: maxRecoveryAttempts + 1,
+			want:     recoveryPlan{reason: "the app quit during processing 4 times"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("data"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			task := &types.Task{
+				URL:              tt.url,
+				SourceLang:       "en",
+				WorkDir:          dir,
+				Status:           tt.status,
+				Progress:         tt.progress,
+				RecoveryAttempts: tt.attempts,
+			}
+			if got := planRecovery(task); got != tt.want {
+				t.Errorf("planRecovery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRecoveryMissingWorkDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "meta.json")
+	if err := os.WriteFile(file, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"", filepath.Join(t.TempDir(), "gone"), file} {
+		task := &types.Task{URL: "https://example.com/v", WorkDir: dir, Status: types.TaskStatusDownloading, RecoveryAttempts: maxRecoveryAttempts + 1}
+		if got := planRecovery(task); got.reason != reasonMissingWorkDir {
+			t.Errorf("planRecovery() with work dir %q = %+v, want %q", dir, got, reasonMissingWorkDir)
+		}
+	}
+}